@@ -1,16 +1,22 @@
 module kubectl-go-mcp-server
 
-go 1.24.4
+go 1.25.5
 
 require (
-	github.com/mark3labs/mcp-go v0.32.0
+	github.com/itchyny/gojq v0.12.19
+	github.com/mark3labs/mcp-go v0.54.1
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.2
 	github.com/spf13/cobra v1.8.0
 	github.com/spf13/pflag v1.0.5
+	sigs.k8s.io/yaml v1.4.0
 )
 
 require (
+	github.com/google/jsonschema-go v0.4.2 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/itchyny/timefmt-go v0.1.8 // indirect
 	github.com/spf13/cast v1.7.1 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
+	golang.org/x/text v0.14.0 // indirect
 )
@@ -3,6 +3,8 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -12,14 +14,17 @@ import (
 	"github.com/spf13/pflag"
 	"kubectl-go-mcp-server/internal/config"
 	"kubectl-go-mcp-server/internal/mcp"
+	"kubectl-go-mcp-server/pkg/kubectl"
 )
 
 type Options struct {
 	KubeConfigPath string `json:"kubeConfigPath,omitempty"`
+	ConfigPath     string `json:"configPath,omitempty"`
 }
 
 func (o *Options) BindCLIFlags(f *pflag.FlagSet) error {
 	f.StringVar(&o.KubeConfigPath, "kubeconfig", o.KubeConfigPath, "path to kubeconfig file")
+	f.StringVar(&o.ConfigPath, "config", o.ConfigPath, "path to server config file (JSON)")
 	return nil
 }
 
@@ -69,13 +74,76 @@ func StartMCPServer(ctx context.Context, opt Options) error {
 		return fmt.Errorf("error creating work directory: %w", err)
 	}
 
-	server, err := mcp.NewServer(opt.KubeConfigPath, workDir)
+	cfg, err := config.Load(opt.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	if cfg.Logging.File != "" {
+		logFile, err := os.OpenFile(cfg.Logging.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("opening log file: %w", err)
+		}
+		defer logFile.Close()
+		log.SetOutput(io.MultiWriter(os.Stderr, logFile))
+		defer log.SetOutput(os.Stderr)
+	}
+
+	// The --kubeconfig flag takes precedence over the config file
+	kubeConfigPath := opt.KubeConfigPath
+	if kubeConfigPath == "" && cfg.Kubeconfig.Path != "" {
+		kubeConfigPath = cfg.GetKubeconfigPath()
+	}
+
+	kubectl.SetEnv(cfg.Kubectl.Env, cfg.Kubectl.ProxyEnv)
+	if cfg.Debug {
+		log.Printf("[debug] kubectl environment: %s", kubectl.DescribeEnv(kubectl.ChildEnv()))
+	}
+
+	binary, err := kubectl.ResolveBinary(ctx, cfg.GetKubectlPath())
+	if err != nil {
+		return fmt.Errorf("kubectl is not usable: %w", err)
+	}
+	kubectl.PinBinary(binary)
+	log.Printf("Using kubectl %s at %s", binary.ClientVersion.GitVersion, binary.Path)
+
+	server, err := mcp.NewServer(kubeConfigPath, workDir, mcp.WithConfig(cfg), mcp.WithKubectl(binary))
 	if err != nil {
 		return fmt.Errorf("creating mcp server: %w", err)
 	}
+	if opt.ConfigPath != "" {
+		go reloadConfigOnHangup(ctx, opt.ConfigPath, server)
+	}
 	return server.Serve(ctx)
 }
 
+// reloadConfigOnHangup reloads the config file on SIGHUP, so that most
+// settings can change without restarting the server; see Server.ApplyConfig.
+func reloadConfigOnHangup(ctx context.Context, configPath string, server *mcp.Server) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+		}
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			log.Printf("Error reloading config: %v", err)
+			continue
+		}
+		if err := server.ApplyConfig(cfg); err != nil {
+			log.Printf("Error applying reloaded config: %v", err)
+			continue
+		}
+		log.Printf("Reloaded config from %s", configPath)
+	}
+}
+
 func Main(version, commit, date string) {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
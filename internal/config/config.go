@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Config struct {
@@ -16,6 +17,22 @@ type Config struct {
 	Kubeconfig KubeconfigSettings `json:"kubeconfig"`
 
 	MCP MCPSettings `json:"mcp"`
+
+	Scale ScaleSettings `json:"scale"`
+
+	ResourceUsage ResourceUsageSettings `json:"resourceUsage"`
+
+	Prompts PromptSettings `json:"prompts"`
+
+	Logging LoggingSettings `json:"logging"`
+
+	Tools ToolSettings `json:"tools"`
+
+	Output OutputSettings `json:"output"`
+
+	Cache CacheSettings `json:"cache"`
+
+	Kubectl KubectlSettings `json:"kubectl"`
 }
 
 type KubeconfigSettings struct {
@@ -28,6 +45,86 @@ type MCPSettings struct {
 	MaxConcurrentOps int  `json:"maxConcurrentOps,omitempty"`
 	OperationTimeout int  `json:"operationTimeout,omitempty"`
 	AllowDestructive bool `json:"allowDestructive,omitempty"`
+	MaxWatches       int  `json:"maxWatches,omitempty"`
+	ReadOnly         bool `json:"readOnly,omitempty"`
+}
+
+// ScaleSettings bounds the replica counts the scale_workload tool may set.
+// A MaxReplicas or MaxDeltaPercent of zero disables that check.
+type ScaleSettings struct {
+	MinReplicas     int `json:"minReplicas"`
+	MaxReplicas     int `json:"maxReplicas,omitempty"`
+	MaxDeltaPercent int `json:"maxDeltaPercent,omitempty"`
+}
+
+// ResourceUsageSettings sets the utilization percentage, from 1 to 100, above
+// which the resource_usage tool flags nodes and pods when a call does not set
+// its own.
+type ResourceUsageSettings struct {
+	ThresholdPercent int `json:"thresholdPercent"`
+}
+
+// PromptSettings points at a directory of *.tmpl prompt templates that are
+// served alongside the built-in prompts.
+type PromptSettings struct {
+	Dir string `json:"dir,omitempty"`
+}
+
+// LoggingSettings configures the local log sink. Server logs always go to
+// stderr; File, if set, receives a copy.
+type LoggingSettings struct {
+	File string `json:"file,omitempty"`
+}
+
+// ToolSettings selects the tools offered to clients by name. When Enabled is
+// set only those tools are offered; Disabled tools are never offered.
+type ToolSettings struct {
+	Enabled  []string `json:"enabled,omitempty"`
+	Disabled []string `json:"disabled,omitempty"`
+}
+
+// OutputSettings caps the command output returned by the kubectl tool. Zero
+// disables a limit. With Paginate, output over the limits is kept for
+// PageTTLSeconds and returned in pages through fetch_output; otherwise the
+// head and tail are kept. MaxProcessBytes bounds the output kept whole for
+// normalizing, filtering or table parsing; zero means ten times MaxBytes.
+type OutputSettings struct {
+	MaxBytes        int  `json:"maxBytes"`
+	MaxLines        int  `json:"maxLines"`
+	Paginate        bool `json:"paginate"`
+	PageTTLSeconds  int  `json:"pageTTLSeconds,omitempty"`
+	MaxProcessBytes int  `json:"maxProcessBytes,omitempty"`
+
+	Normalize NormalizeSettings `json:"normalize"`
+}
+
+// NormalizeSettings controls the cleanup of -o yaml and -o json output.
+// StripFields are dotted field paths removed from every object, with keys
+// that contain dots written as ["key"]; when unset, managedFields, the
+// last-applied-configuration annotation, resourceVersion and uid are removed.
+// CompactJSON rewrites the output as single-line JSON.
+type NormalizeSettings struct {
+	Enabled     bool     `json:"enabled"`
+	StripFields []string `json:"stripFields,omitempty"`
+	CompactJSON bool     `json:"compactJSON,omitempty"`
+}
+
+// CacheSettings controls the cache of read-only kubectl command results.
+// A TTLSeconds of zero disables it.
+type CacheSettings struct {
+	TTLSeconds int `json:"ttlSeconds"`
+}
+
+// KubectlSettings selects the kubectl executable commands run and the
+// environment it runs with. When Path is empty, kubectl is looked up on PATH
+// at startup. Env names the variables passed on, with a trailing "*"
+// matching a prefix; when unset, PATH, HOME and the variables of the EKS,
+// GKE and AKS credential plugins are passed. ProxyEnv also passes
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+type KubectlSettings struct {
+	Path     string   `json:"path,omitempty"`
+	Env      []string `json:"env,omitempty"`
+	ProxyEnv bool     `json:"proxyEnv,omitempty"`
 }
 
 func Load(configPath string) (*Config, error) {
@@ -48,10 +145,59 @@ func Load(configPath string) (*Config, error) {
 	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
 
 	return cfg, nil
 }
 
+// Validate checks the settings that cannot be checked when the config is
+// parsed.
+func (c *Config) Validate() error {
+	if threshold := c.ResourceUsage.ThresholdPercent; threshold < 1 || threshold > 100 {
+		return fmt.Errorf("resourceUsage.thresholdPercent must be between 1 and 100, got %d", threshold)
+	}
+	for _, field := range c.Output.Normalize.StripFields {
+		if _, err := ParseFieldPath(field); err != nil {
+			return fmt.Errorf("output.normalize.stripFields: %w", err)
+		}
+	}
+	return nil
+}
+
+// ParseFieldPath splits a dotted field path, such as
+// metadata.annotations["kubectl.kubernetes.io/last-applied-configuration"],
+// into its keys.
+func ParseFieldPath(field string) ([]string, error) {
+	var path []string
+	rest := field
+	for rest != "" {
+		if strings.HasPrefix(rest, `["`) {
+			end := strings.Index(rest, `"]`)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid field path %q: unterminated [\"", field)
+			}
+			path = append(path, rest[2:end])
+			rest = strings.TrimPrefix(rest[end+2:], ".")
+			continue
+		}
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("invalid field path %q: empty field name", field)
+		}
+		path = append(path, rest[:end])
+		rest = strings.TrimPrefix(rest[end:], ".")
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("invalid field path %q", field)
+	}
+	return path, nil
+}
+
 func (c *Config) Save(configPath string) error {
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
@@ -85,6 +231,27 @@ func DefaultConfig() *Config {
 			MaxConcurrentOps: 5,
 			OperationTimeout: 30,
 			AllowDestructive: false,
+			MaxWatches:       10,
+		},
+		Scale: ScaleSettings{
+			MinReplicas:     1,
+			MaxReplicas:     50,
+			MaxDeltaPercent: 100,
+		},
+		ResourceUsage: ResourceUsageSettings{
+			ThresholdPercent: 80,
+		},
+		Output: OutputSettings{
+			MaxBytes:       100000,
+			MaxLines:       2000,
+			Paginate:       true,
+			PageTTLSeconds: 600,
+			Normalize: NormalizeSettings{
+				Enabled: true,
+			},
+		},
+		Cache: CacheSettings{
+			TTLSeconds: 15,
 		},
 	}
 }
@@ -99,3 +266,15 @@ func (c *Config) GetKubeconfigPath() string {
 
 	return GetDefaultKubeconfigPath()
 }
+
+// GetKubectlPath returns the configured kubectl path with "~" and environment
+// variables expanded, or "" to look kubectl up on PATH.
+func (c *Config) GetKubectlPath() string {
+	if c.Kubectl.Path == "" {
+		return ""
+	}
+	if expanded, err := expandPath(c.Kubectl.Path); err == nil {
+		return expanded
+	}
+	return c.Kubectl.Path
+}
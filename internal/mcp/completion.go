@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"kubectl-go-mcp-server/pkg/kubectl"
+)
+
+const (
+	// completionCacheTTL keeps kubectl from being run on every keystroke
+	// while a user types an argument.
+	completionCacheTTL = 30 * time.Second
+
+	// maxCompletionValues is the most values MCP allows in one response.
+	maxCompletionValues = 100
+
+	// maxCompletionCacheEntries bounds the cache, which gets a key per
+	// context, namespace and kind completed.
+	maxCompletionCacheEntries = 256
+)
+
+type completionEntry struct {
+	values    []string
+	fetchedAt time.Time
+}
+
+// completer answers completion requests for prompt and resource template
+// arguments from the cluster, caching what kubectl returns for a short time.
+type completer struct {
+	server *Server
+
+	mu    sync.Mutex
+	cache map[string]completionEntry
+}
+
+func newCompleter(s *Server) *completer {
+	return &completer{server: s, cache: make(map[string]completionEntry)}
+}
+
+func (c *completer) CompletePromptArgument(ctx context.Context, promptName string, argument mcp.CompleteArgument, completeContext mcp.CompleteContext) (*mcp.Completion, error) {
+	return c.complete(ctx, argument, completeContext.Arguments, false), nil
+}
+
+func (c *completer) CompleteResourceArgument(ctx context.Context, uri string, argument mcp.CompleteArgument, completeContext mcp.CompleteContext) (*mcp.Completion, error) {
+	return c.complete(ctx, argument, completeContext.Arguments, true), nil
+}
+
+// complete returns candidates for the argument named argument.Name, using the
+// arguments already resolved to scope them: names are listed for the given
+// kind, namespace and context.
+func (c *completer) complete(ctx context.Context, argument mcp.CompleteArgument, resolved map[string]string, resourceTemplate bool) *mcp.Completion {
+	ref := kubectl.ObjectRef{Context: resolved["context"], Namespace: resolved["namespace"], Kind: resolved["kind"]}
+	if ref.Context == defaultSegment {
+		ref.Context = ""
+	}
+	if ref.Namespace == defaultSegment {
+		ref.Namespace = ""
+	}
+
+	var values []string
+	switch argument.Name {
+	case "context":
+		values = c.lines(ctx, "kubectl config get-contexts -o name")
+	case "namespace":
+		values = c.names(ctx, kubectl.ObjectRef{Context: ref.Context, Kind: "namespaces"})
+	case "kind":
+		values = c.kinds(ctx)
+	case "name":
+		if ref.Kind != "" {
+			values = c.names(ctx, ref)
+		}
+	case "pod", "node":
+		ref.Kind = argument.Name + "s"
+		values = c.names(ctx, ref)
+	}
+
+	// "_" selects the current context or cluster scope in resource URIs.
+	if resourceTemplate && (argument.Name == "context" || argument.Name == "namespace") {
+		values = append([]string{defaultSegment}, values...)
+	}
+	return filterCompletions(values, argument.Value)
+}
+
+func (c *completer) kinds(ctx context.Context) []string {
+	return c.cached("api-resources", func() []string {
+		s := c.server
+		resources, err := s.discovery.Resources(ctx, s.workDir, s.kubectlConfig)
+		if err != nil {
+			log.Printf("Completing kinds from built-in resources: discovery failed: %v", err)
+			resources = kubectl.BuiltinResources()
+		}
+
+		values := make([]string, 0, len(resources))
+		for _, r := range resources {
+			values = append(values, r.Name)
+		}
+		return values
+	})
+}
+
+// names lists object names of ref's kind from "kubectl get -o name", which
+// prints them as "<kind>/<name>".
+func (c *completer) names(ctx context.Context, ref kubectl.ObjectRef) []string {
+	command, err := c.server.discovery.NamesCommand(ref)
+	if err != nil {
+		return nil
+	}
+
+	lines := c.lines(ctx, command)
+	names := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if _, name, ok := strings.Cut(line, "/"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// lines runs command and returns the non-empty output lines. Failures yield
+// no lines.
+func (c *completer) lines(ctx context.Context, command string) []string {
+	return c.cached(command, func() []string {
+		result, err := kubectl.RunKubectlCommand(ctx, command, c.server.workDir, c.server.kubectlConfig)
+		if err != nil {
+			log.Printf("Completion command failed: command=%s, error=%v", command, err)
+			return nil
+		}
+		if result.Error != "" {
+			log.Printf("Completion command failed: command=%s, error=%s", command, result.Error)
+			return nil
+		}
+
+		var values []string
+		for _, line := range strings.Split(result.Stdout, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				values = append(values, line)
+			}
+		}
+		return values
+	})
+}
+
+// cached returns the values fetched for key within completionCacheTTL, or
+// fetches them. Empty results from failures are cached too, so an unreachable
+// cluster is not retried on every keystroke. Expired entries are dropped when
+// values are stored, and the oldest entry when the cache is full.
+func (c *completer) cached(key string, fetch func() []string) []string {
+	c.mu.Lock()
+	entry, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < completionCacheTTL {
+		return entry.values
+	}
+
+	values := fetch()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	oldest := ""
+	for k, entry := range c.cache {
+		if now.Sub(entry.fetchedAt) >= completionCacheTTL {
+			delete(c.cache, k)
+		} else if oldest == "" || entry.fetchedAt.Before(c.cache[oldest].fetchedAt) {
+			oldest = k
+		}
+	}
+	if len(c.cache) >= maxCompletionCacheEntries {
+		delete(c.cache, oldest)
+	}
+	c.cache[key] = completionEntry{values: values, fetchedAt: now}
+	return values
+}
+
+// filterCompletions returns the sorted, de-duplicated values starting with
+// prefix, truncated to the MCP limit.
+func filterCompletions(values []string, prefix string) *mcp.Completion {
+	seen := make(map[string]bool, len(values))
+	matches := []string{}
+	prefix = strings.ToLower(prefix)
+	for _, v := range values {
+		if !seen[v] && strings.HasPrefix(strings.ToLower(v), prefix) {
+			seen[v] = true
+			matches = append(matches, v)
+		}
+	}
+	sort.Strings(matches)
+
+	completion := &mcp.Completion{Values: matches, Total: len(matches)}
+	if len(matches) > maxCompletionValues {
+		completion.Values = matches[:maxCompletionValues]
+		completion.HasMore = true
+	}
+	return completion
+}
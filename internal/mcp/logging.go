@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const loggerName = "kubectl-go-mcp-server"
+
+// clientLog writes message and fields to the local log and sends them to the
+// client in ctx as a notifications/message, if the client's logging/setLevel
+// level lets it through. Clients that never set a level get errors only.
+func (s *Server) clientLog(ctx context.Context, level mcp.LoggingLevel, message string, fields map[string]any) {
+	log.Print(formatLogLine(level, message, fields))
+
+	err := s.server.SendLogMessageToClient(ctx, logNotification(level, message, fields))
+	if err != nil && !ignorableLogError(err) {
+		log.Printf("Error sending log message to client: %v", err)
+	}
+}
+
+// sessionLog is clientLog for work not tied to a request, such as watches.
+func (s *Server) sessionLog(sessions []string, level mcp.LoggingLevel, message string, fields map[string]any) {
+	log.Print(formatLogLine(level, message, fields))
+
+	notification := logNotification(level, message, fields)
+	for _, session := range sessions {
+		if err := s.server.SendLogMessageToSpecificClient(session, notification); err != nil && !ignorableLogError(err) {
+			log.Printf("Error sending log message to session %s: %v", session, err)
+		}
+	}
+}
+
+// ignorableLogError reports whether err only means there is no client that
+// can receive log messages, e.g. for requests handled outside a session.
+func ignorableLogError(err error) bool {
+	return errors.Is(err, server.ErrNotificationNotInitialized) ||
+		errors.Is(err, server.ErrSessionNotInitialized) ||
+		errors.Is(err, server.ErrSessionDoesNotSupportLogging)
+}
+
+func logNotification(level mcp.LoggingLevel, message string, fields map[string]any) mcp.LoggingMessageNotification {
+	data := make(map[string]any, len(fields)+1)
+	for k, v := range fields {
+		data[k] = v
+	}
+	data["message"] = message
+	return mcp.NewLoggingMessageNotification(level, loggerName, data)
+}
+
+// formatLogLine renders fields sorted by key, e.g.
+// "[warning] Tool call denied: error=..., tool=kubectl".
+func formatLogLine(level mcp.LoggingLevel, message string, fields map[string]any) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, fields[k]))
+	}
+	line := fmt.Sprintf("[%s] %s", level, message)
+	if len(pairs) > 0 {
+		line += ": " + strings.Join(pairs, ", ")
+	}
+	return line
+}
+
+// toolErrorEvent names the kind of failure reported in a tool result's error.
+func toolErrorEvent(msg string) string {
+	switch {
+	case strings.HasPrefix(msg, "Security violation"), strings.HasPrefix(msg, "Security validation failed"):
+		return "Command denied by security validation"
+	case strings.HasPrefix(msg, "command cancelled"):
+		return "Command cancelled or timed out"
+	default:
+		return "Tool call returned an error"
+	}
+}
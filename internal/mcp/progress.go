@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+// progressInterval limits how often progress is sent, so a command printing
+// a large JSON document does not flood the client. Lines in between are only
+// counted; the full output is still in the tool result.
+const progressInterval = 200 * time.Millisecond
+
+// withProgress returns ctx carrying a types.ProgressFunc that sends output
+// lines to the client as notifications/progress, when the request carries a
+// progress token.
+func (s *Server) withProgress(ctx context.Context, request mcp.CallToolRequest) context.Context {
+	meta := request.Params.Meta
+	if meta == nil || meta.ProgressToken == nil {
+		return ctx
+	}
+	token := meta.ProgressToken
+
+	var mu sync.Mutex
+	var lines int
+	var lastSent time.Time
+	report := func(message string) {
+		mu.Lock()
+		lines++
+		progress := lines
+		if time.Since(lastSent) < progressInterval {
+			mu.Unlock()
+			return
+		}
+		lastSent = time.Now()
+		mu.Unlock()
+
+		params := map[string]any{
+			"progressToken": token,
+			"progress":      progress,
+			"message":       message,
+		}
+		if err := s.server.SendNotificationToClient(ctx, string(mcp.MethodNotificationProgress), params); err != nil {
+			log.Printf("Error sending progress notification: %v", err)
+		}
+	}
+	return context.WithValue(ctx, types.ProgressKey, types.ProgressFunc(report))
+}
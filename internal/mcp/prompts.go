@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"sigs.k8s.io/yaml"
+)
+
+const promptFileExtension = ".tmpl"
+
+//go:embed prompts/*.tmpl
+var builtinPromptFiles embed.FS
+
+var promptNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptTemplate is an MCP prompt defined by a template file: YAML front
+// matter between "---" lines followed by a text/template body that is
+// executed with the prompt arguments.
+type PromptTemplate struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+
+	template *template.Template
+}
+
+// BuiltinPrompts returns the prompts shipped with the server.
+func BuiltinPrompts() ([]*PromptTemplate, error) {
+	sub, err := fs.Sub(builtinPromptFiles, "prompts")
+	if err != nil {
+		return nil, err
+	}
+	return loadPrompts(sub)
+}
+
+// LoadPromptTemplates parses every *.tmpl file in dir. The file name, without
+// extension, is the prompt name unless the front matter sets one.
+func LoadPromptTemplates(dir string) ([]*PromptTemplate, error) {
+	return loadPrompts(os.DirFS(dir))
+}
+
+func loadPrompts(fsys fs.FS) ([]*PromptTemplate, error) {
+	files, err := fs.Glob(fsys, "*"+promptFileExtension)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	prompts := make([]*PromptTemplate, 0, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		prompt, err := ParsePromptTemplate(strings.TrimSuffix(path.Base(file), promptFileExtension), data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		prompts = append(prompts, prompt)
+	}
+	return prompts, nil
+}
+
+// ParsePromptTemplate parses a prompt template file; name is used when the
+// front matter does not set one.
+func ParsePromptTemplate(name string, data []byte) (*PromptTemplate, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return nil, fmt.Errorf("prompt template must start with a --- front matter line")
+	}
+	frontMatter, body, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		return nil, fmt.Errorf("prompt template front matter is not terminated by a --- line")
+	}
+
+	prompt := &PromptTemplate{Name: name}
+	if err := yaml.UnmarshalStrict([]byte(frontMatter), prompt); err != nil {
+		return nil, fmt.Errorf("parsing front matter: %w", err)
+	}
+	if !promptNamePattern.MatchString(prompt.Name) {
+		return nil, fmt.Errorf("invalid prompt name %q", prompt.Name)
+	}
+	seen := make(map[string]bool)
+	for _, arg := range prompt.Arguments {
+		if arg.Name == "" || seen[arg.Name] {
+			return nil, fmt.Errorf("prompt %s: argument names must be unique and non-empty", prompt.Name)
+		}
+		seen[arg.Name] = true
+	}
+
+	tmpl, err := template.New(prompt.Name).Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	prompt.template = tmpl
+	return prompt, nil
+}
+
+// Render executes the template. Declared arguments that are not supplied
+// render as empty strings; missing required arguments are an error.
+func (p *PromptTemplate) Render(args map[string]string) (string, error) {
+	data := make(map[string]string, len(p.Arguments))
+	for _, arg := range p.Arguments {
+		value := args[arg.Name]
+		if arg.Required && strings.TrimSpace(value) == "" {
+			return "", fmt.Errorf("prompt %s requires argument %q", p.Name, arg.Name)
+		}
+		data[arg.Name] = value
+	}
+
+	var out bytes.Buffer
+	if err := p.template.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("rendering prompt %s: %w", p.Name, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// promptTemplates returns the built-in prompts plus those in dir, if set; a
+// custom prompt replaces a built-in one with the same name.
+func promptTemplates(dir string) ([]*PromptTemplate, error) {
+	prompts, err := BuiltinPrompts()
+	if err != nil {
+		return nil, fmt.Errorf("loading built-in prompts: %w", err)
+	}
+	if dir != "" {
+		custom, err := LoadPromptTemplates(dir)
+		if err != nil {
+			return nil, fmt.Errorf("loading prompts from %s: %w", dir, err)
+		}
+		prompts = append(prompts, custom...)
+	}
+
+	byName := make(map[string]int, len(prompts))
+	merged := prompts[:0]
+	for _, prompt := range prompts {
+		if i, ok := byName[prompt.Name]; ok {
+			merged[i] = prompt
+			continue
+		}
+		byName[prompt.Name] = len(merged)
+		merged = append(merged, prompt)
+	}
+	return merged, nil
+}
+
+// serverPrompts returns the prompts to register: the built-in ones and those
+// in dir.
+func (s *Server) serverPrompts(dir string) ([]server.ServerPrompt, error) {
+	prompts, err := promptTemplates(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var serverPrompts []server.ServerPrompt
+	for _, prompt := range prompts {
+		opts := []mcp.PromptOption{mcp.WithPromptDescription(prompt.Description)}
+		for _, arg := range prompt.Arguments {
+			argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.Description)}
+			if arg.Required {
+				argOpts = append(argOpts, mcp.RequiredArgument())
+			}
+			opts = append(opts, mcp.WithArgument(arg.Name, argOpts...))
+		}
+
+		serverPrompts = append(serverPrompts, server.ServerPrompt{
+			Prompt: mcp.NewPrompt(prompt.Name, opts...),
+			Handler: func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+				text, err := prompt.Render(request.Params.Arguments)
+				if err != nil {
+					return nil, err
+				}
+				return mcp.NewGetPromptResult(prompt.Description, []mcp.PromptMessage{
+					mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(text)),
+				}), nil
+			},
+		})
+	}
+	return serverPrompts, nil
+}
@@ -0,0 +1,184 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	resourceScheme = "k8s://"
+
+	// defaultSegment stands for the current context in the context segment
+	// and for cluster scope (or all namespaces) in the namespace segment.
+	defaultSegment = "_"
+
+	objectURITemplate = resourceScheme + "{context}/{namespace}/{kind}/{name}"
+	listURITemplate   = resourceScheme + "{context}/{namespace}/{kind}"
+)
+
+// ResourceListEntry is one object in a list resource, with the URI to read it.
+type ResourceListEntry struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	URI       string `json:"uri"`
+}
+
+type ResourceList struct {
+	Kind  string              `json:"kind"`
+	Items []ResourceListEntry `json:"items"`
+	Count int                 `json:"count"`
+}
+
+// ObjectURI returns the resource URI for an object; empty context and
+// namespace are written as "_".
+func ObjectURI(ref kubectl.ObjectRef) string {
+	segments := []string{segment(ref.Context), segment(ref.Namespace), ref.Kind}
+	if ref.Name != "" {
+		segments = append(segments, ref.Name)
+	}
+	return resourceScheme + strings.Join(segments, "/")
+}
+
+// ParseResourceURI parses a k8s:// URI for a single object or a list.
+func ParseResourceURI(uri string) (kubectl.ObjectRef, error) {
+	rest, ok := strings.CutPrefix(uri, resourceScheme)
+	if !ok {
+		return kubectl.ObjectRef{}, fmt.Errorf("resource URI must start with %s: %q", resourceScheme, uri)
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 && len(parts) != 4 {
+		return kubectl.ObjectRef{}, fmt.Errorf("resource URI must look like %s or %s: %q", objectURITemplate, listURITemplate, uri)
+	}
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil || unescaped == "" {
+			return kubectl.ObjectRef{}, fmt.Errorf("invalid segment %q in resource URI %q", part, uri)
+		}
+		parts[i] = unescaped
+	}
+
+	ref := kubectl.ObjectRef{Context: parts[0], Namespace: parts[1], Kind: parts[2]}
+	if ref.Context == defaultSegment {
+		ref.Context = ""
+	}
+	if ref.Namespace == defaultSegment {
+		ref.Namespace = ""
+	}
+	if len(parts) == 4 {
+		ref.Name = parts[3]
+	}
+	return ref, nil
+}
+
+func (s *Server) registerResources() {
+	s.server.AddResourceTemplate(mcp.NewResourceTemplate(
+		objectURITemplate,
+		"Kubernetes object",
+		mcp.WithTemplateDescription(`A single object as YAML. Use "_" as the context for the current context and as the namespace for cluster-scoped objects. Secret values are redacted.`),
+		mcp.WithTemplateMIMEType("application/yaml"),
+	), s.handleReadResource)
+
+	s.server.AddResourceTemplate(mcp.NewResourceTemplate(
+		listURITemplate,
+		"Kubernetes object list",
+		mcp.WithTemplateDescription(`The objects of one kind in a namespace, with the URI of each. Use "_" as the context for the current context and as the namespace for cluster-scoped kinds or all namespaces.`),
+		mcp.WithTemplateMIMEType("application/json"),
+	), s.handleReadResource)
+}
+
+func (s *Server) handleReadResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	uri := request.Params.URI
+	ref, err := ParseResourceURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	command, resource, err := s.discovery.GetCommand(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Reading resource: uri=%s, command=%s", uri, command)
+
+	execResult, err := kubectl.RunKubectlCommand(ctx, command, s.workDir, s.kubectlConfig)
+	if err != nil {
+		return nil, err
+	}
+	if execResult.Error != "" {
+		return nil, fmt.Errorf("%s: %s", execResult.Error, strings.TrimSpace(execResult.Stdout))
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(execResult.Stdout), &obj); err != nil {
+		return nil, fmt.Errorf("parsing kubectl output: %w", err)
+	}
+	kubectl.RedactSecrets(obj)
+
+	if ref.Name == "" {
+		return listContents(uri, ref, resource, obj)
+	}
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("converting %s to YAML: %w", uri, err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: "application/yaml", Text: string(data)},
+	}, nil
+}
+
+func listContents(uri string, ref kubectl.ObjectRef, resource kubectl.APIResource, obj map[string]any) ([]mcp.ResourceContents, error) {
+	list := ResourceList{Kind: resource.Kind, Items: []ResourceListEntry{}}
+
+	items, _ := obj["items"].([]any)
+	for _, item := range items {
+		object, _ := item.(map[string]any)
+		metadata, _ := object["metadata"].(map[string]any)
+		name, _ := metadata["name"].(string)
+		namespace, _ := metadata["namespace"].(string)
+		if name == "" {
+			continue
+		}
+		list.Items = append(list.Items, ResourceListEntry{
+			Name:      name,
+			Namespace: namespace,
+			URI:       ObjectURI(kubectl.ObjectRef{Context: ref.Context, Namespace: namespace, Kind: ref.Kind, Name: name}),
+		})
+	}
+	list.Count = len(list.Items)
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling %s: %w", uri, err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: "application/json", Text: string(data)},
+	}, nil
+}
+
+// segment percent-encodes everything but unreserved characters, so that
+// context names such as "admin@prod" still match the URI templates.
+func segment(value string) string {
+	if value == "" {
+		return defaultSegment
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"kubectl-go-mcp-server/internal/config"
 	"kubectl-go-mcp-server/pkg/kubectl"
 	"kubectl-go-mcp-server/pkg/types"
 )
@@ -15,45 +19,136 @@ import (
 type Server struct {
 	kubectlConfig string
 	server        *server.MCPServer
-	tools         *Tools
 	workDir       string
+	discovery     *kubectl.DiscoveryCache
+	outputs       *kubectl.OutputStore
+	results       *kubectl.ResultCache
+	kubectl       *kubectl.Binary
+	// explain is kept across tool updates for its cache of explanations.
+	explain *kubectl.ExplainTool
+
+	// mu guards the config and the tool list, which change on reload.
+	mu          sync.RWMutex
+	config      *config.Config
+	tools       *Tools
+	deniedTools map[string]bool
+
+	watchMu           sync.Mutex
+	watches           map[string]*resourceWatch
+	watchRestartDelay time.Duration
 }
 
-func NewServer(kubectlConfig, workDir string) (*Server, error) {
+type ServerOption func(*Server)
+
+func WithConfig(cfg *config.Config) ServerOption {
+	return func(s *Server) {
+		s.config = cfg
+	}
+}
+
+// WithWatchRestartDelay sets the delay before the first restart of a
+// resource watch whose kubectl exited.
+func WithWatchRestartDelay(delay time.Duration) ServerOption {
+	return func(s *Server) {
+		s.watchRestartDelay = delay
+	}
+}
+
+// WithKubectl records the kubectl that commands run, whose version is
+// reported with command results and checked against the cluster's.
+func WithKubectl(binary *kubectl.Binary) ServerOption {
+	return func(s *Server) {
+		s.kubectl = binary
+	}
+}
+
+func NewServer(kubectlConfig, workDir string, opts ...ServerOption) (*Server, error) {
 	s := &Server{
 		kubectlConfig: kubectlConfig,
-		workDir:       workDir, server: server.NewMCPServer(
-			"kubectl-go-mcp-server",
-			"1.0.0",
-			server.WithToolCapabilities(true),
-		),
-		tools: NewTools(),
-	}
-
-	kubectlTool := &kubectl.KubectlTool{}
-	s.tools.RegisterTool(kubectlTool)
-
-	for _, tool := range s.tools.AllTools() {
-		toolDefn := tool.FunctionDefinition()
-		toolInputSchema, err := toolDefn.Parameters.ToRawSchema()
-		if err != nil {
-			return nil, fmt.Errorf("converting tool schema to json.RawMessage: %w", err)
-		}
+		workDir:       workDir,
+		tools:         NewTools(),
+		config:        config.DefaultConfig(),
+		discovery:     kubectl.NewDiscoveryCache(kubectl.DefaultDiscoveryRefreshInterval),
+		watches:       make(map[string]*resourceWatch),
 
-		s.server.AddTool(mcp.NewToolWithRawSchema(
-			toolDefn.Name,
-			toolDefn.Description,
-			toolInputSchema,
-		), s.handleToolCall)
+		watchRestartDelay: watchRestartDelay,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	if err := s.config.Validate(); err != nil {
+		return nil, err
+	}
+	s.explain = &kubectl.ExplainTool{Discovery: s.discovery}
+	s.outputs = kubectl.NewOutputStore(workDir, time.Duration(s.config.Output.PageTTLSeconds)*time.Second)
+	s.results = kubectl.NewResultCache(time.Duration(s.config.Cache.TTLSeconds) * time.Second)
+
+	hooks := &server.Hooks{}
+	s.registerSubscriptionHooks(hooks)
+	completions := newCompleter(s)
+	s.server = server.NewMCPServer(
+		"kubectl-go-mcp-server",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, false),
+		server.WithPromptCapabilities(false),
+		server.WithCompletions(),
+		server.WithPromptCompletionProvider(completions),
+		server.WithResourceCompletionProvider(completions),
+		server.WithHooks(hooks),
+		server.WithLogging(),
+	)
+
+	if err := s.updateTools(); err != nil {
+		return nil, err
+	}
+	s.registerResources()
+	prompts, err := s.serverPrompts(s.config.Prompts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	s.server.SetPrompts(prompts...)
 
 	return s, nil
 }
 
 func (s *Server) Serve(ctx context.Context) error {
+	go s.discovery.Run(ctx, s.workDir, s.kubectlConfig)
+	go s.watchContext(ctx)
+	go s.outputs.Run(ctx, time.Minute)
+	if s.kubectl != nil {
+		go s.warnVersionSkew(ctx)
+	}
+	defer s.stopWatches()
+	defer s.outputs.Close()
+
 	return server.ServeStdio(s.server)
 }
 
+// warnVersionSkew logs a warning when the cluster is more than one minor
+// version away from kubectl, which kubectl doesn't support.
+func (s *Server) warnVersionSkew(ctx context.Context) {
+	result, err := kubectl.RunKubectlCommand(ctx, "kubectl version -o json", s.workDir, s.kubectlConfig)
+	if err != nil {
+		log.Printf("Failed to get cluster version: %v", err)
+		return
+	}
+	version, err := kubectl.ParseKubectlVersion(result.Stdout)
+	if err != nil || version.ServerVersion == nil {
+		log.Printf("Cluster version unavailable, skipping the version skew check")
+		return
+	}
+	skew, err := kubectl.MinorVersionSkew(s.kubectl.ClientVersion, version.ServerVersion)
+	if err != nil {
+		log.Printf("Skipping the version skew check: %v", err)
+		return
+	}
+	if skew > 1 || skew < -1 {
+		log.Printf("WARNING: kubectl %s at %s and cluster %s are more than one minor version apart, which kubectl doesn't support",
+			s.kubectl.ClientVersion.GitVersion, s.kubectl.Path, version.ServerVersion.GitVersion)
+	}
+}
+
 func (s *Server) GetKubectlConfig() string {
 	return s.kubectlConfig
 }
@@ -62,7 +157,20 @@ func (s *Server) GetWorkDir() string {
 	return s.workDir
 }
 
+func (s *Server) GetConfig() *config.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+func (s *Server) GetMCPServer() *server.MCPServer {
+	return s.server
+}
+
+// GetTools returns the tools currently offered to clients.
 func (s *Server) GetTools() *Tools {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.tools
 }
 
@@ -74,67 +182,68 @@ func (s *Server) handleToolCall(ctx context.Context, request mcp.CallToolRequest
 		return mcp.NewToolResultError("Invalid arguments format: expected a map"), nil
 	}
 
-	commandVal, ok := argMap["command"]
-	if !ok {
-		return mcp.NewToolResultError("Missing required parameter: command"), nil
-	}
-	command, ok := commandVal.(string)
-	if !ok {
-		return mcp.NewToolResultError("Parameter 'command' must be a string"), nil
+	tool := s.GetTools().Lookup(name)
+	if tool == nil {
+		s.clientLog(ctx, mcp.LoggingLevelWarning, "SECURITY WARNING: Attempt to use unregistered tool", map[string]any{"tool": name})
+		return mcp.NewToolResultError(fmt.Sprintf("Tool %s is not permitted", name)), nil
 	}
 
-	var modifiesResource string
-	if modVal, ok := argMap["modifies_resource"]; ok {
-		if modStr, ok := modVal.(string); ok {
-			modifiesResource = modStr
-		}
+	if err := tool.FunctionDefinition().Parameters.Validate(argMap); err != nil {
+		s.clientLog(ctx, mcp.LoggingLevelWarning, "Invalid tool arguments", map[string]any{"tool": name, "error": err.Error()})
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
 	}
 
-	log.Printf("Received tool call: tool=%s, command=%s, modifies_resource=%s", name, command, modifiesResource)
-
-	if name != "kubectl" {
-		log.Printf("SECURITY WARNING: Attempt to use non-kubectl tool: %s", name)
-		return mcp.NewToolResultError(fmt.Sprintf("Only kubectl tool is allowed, tool %s is not permitted", name)), nil
+	loggedArgs := argMap
+	if command, ok := argMap["command"].(string); ok {
+		loggedArgs = maps.Clone(argMap)
+		loggedArgs["command"] = kubectl.MaskSecrets(command)
 	}
+	s.clientLog(ctx, mcp.LoggingLevelInfo, "Received tool call", map[string]any{
+		"tool":              name,
+		"args":              loggedArgs,
+		"modifies_resource": tool.CheckModifiesResource(argMap),
+	})
 
 	ctx = context.WithValue(ctx, types.KubeconfigKey, s.kubectlConfig)
 	ctx = context.WithValue(ctx, types.WorkdirKey, s.workDir)
-
-	tool := s.tools.Lookup(name)
-	if tool == nil {
-		return mcp.NewToolResultError(fmt.Sprintf("Tool %s not found", name)), nil
+	if s.kubectl != nil {
+		ctx = context.WithValue(ctx, types.KubectlVersionKey, s.kubectl.ClientVersion.GitVersion)
 	}
+	ctx = s.withProgress(ctx, request)
 
-	args := map[string]any{
-		"command": command,
-	}
-
-	if modifiesResource != "" {
-		args["modifies_resource"] = modifiesResource
-	}
-
-	output, err := tool.Run(ctx, args)
+	output, err := tool.Run(ctx, argMap)
 	if err != nil {
-		log.Printf("Error running tool call: %v", err)
+		s.clientLog(ctx, mcp.LoggingLevelError, "Error running tool call", map[string]any{"tool": name, "error": err.Error()})
 		return mcp.NewToolResultError(fmt.Sprintf("Error running tool: %v", err)), nil
 	}
 
 	result, err := ToolResultToMap(output)
 	if err != nil {
-		log.Printf("Error converting tool call output to result: %v", err)
+		s.clientLog(ctx, mcp.LoggingLevelError, "Error converting tool call output to result", map[string]any{"tool": name, "error": err.Error()})
 		return mcp.NewToolResultError(fmt.Sprintf("Error processing result: %v", err)), nil
 	}
 
 	log.Printf("Tool call output: tool=%s, result=%v", name, result)
+	if msg, _ := result["error"].(string); msg != "" {
+		s.clientLog(ctx, mcp.LoggingLevelWarning, toolErrorEvent(msg), map[string]any{"tool": name, "error": msg})
+	}
 
-	return &mcp.CallToolResult{
+	callResult := &mcp.CallToolResult{
 		Content: []mcp.Content{
 			mcp.TextContent{
 				Type: "text",
 				Text: fmt.Sprintf("%v", result),
 			},
 		},
-	}, nil
+	}
+	if outputSchema := tool.FunctionDefinition().OutputSchema; outputSchema != nil {
+		if err := outputSchema.Validate(result); err != nil {
+			s.clientLog(ctx, mcp.LoggingLevelError, "Tool output does not match its output schema", map[string]any{"tool": name, "error": err.Error()})
+			return mcp.NewToolResultError(fmt.Sprintf("Error processing result: output does not match schema: %v", err)), nil
+		}
+		callResult.StructuredContent = result
+	}
+	return callResult, nil
 }
 
 type Tools struct {
@@ -0,0 +1,234 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"kubectl-go-mcp-server/pkg/kubectl"
+)
+
+// A watch whose kubectl process exits, e.g. because the API server closed
+// the connection, is restarted after watchRestartDelay, doubled after each
+// consecutive failure up to maxWatchRestartDelay. After maxWatchFailures
+// consecutive failures, such as for an object that does not exist, the watch
+// is stopped. A run that reported a change resets the count.
+const (
+	watchRestartDelay    = 5 * time.Second
+	maxWatchRestartDelay = 5 * time.Minute
+	maxWatchFailures     = 5
+)
+
+// resourceWatch is one "kubectl get --watch-only" process shared by every
+// session subscribed to the same URI.
+type resourceWatch struct {
+	cancel   context.CancelFunc
+	sessions map[string]bool
+}
+
+func (s *Server) registerSubscriptionHooks(hooks *server.Hooks) {
+	// Subscriptions are set up before the request is handled so that invalid
+	// URIs and requests over the watch limit are rejected with an error.
+	hooks.AddOnRequestInitialization(func(ctx context.Context, id any, message any) error {
+		raw, ok := message.(json.RawMessage)
+		if !ok {
+			return nil
+		}
+		var request struct {
+			Method string `json:"method"`
+			Params struct {
+				URI string `json:"uri"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(raw, &request); err != nil || request.Method != string(mcp.MethodResourcesSubscribe) {
+			return nil
+		}
+		return s.subscribe(sessionID(ctx), request.Params.URI)
+	})
+	hooks.AddAfterUnsubscribe(func(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+		s.unsubscribe(sessionID(ctx), message.Params.URI)
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		s.unsubscribeSession(session.SessionID())
+	})
+}
+
+// ActiveWatches returns the number of running resource watches.
+func (s *Server) ActiveWatches() int {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	return len(s.watches)
+}
+
+func (s *Server) subscribe(session, uri string) error {
+	ref, err := ParseResourceURI(uri)
+	if err != nil {
+		return err
+	}
+	command, err := s.discovery.WatchCommand(ref)
+	if err != nil {
+		return err
+	}
+
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	if w, ok := s.watches[uri]; ok {
+		w.sessions[session] = true
+		return nil
+	}
+	if limit := s.GetConfig().MCP.MaxWatches; limit > 0 && len(s.watches) >= limit {
+		return fmt.Errorf("too many active subscriptions (limit %d)", limit)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.watches[uri] = &resourceWatch{cancel: cancel, sessions: map[string]bool{session: true}}
+	go s.runWatch(ctx, uri, command)
+
+	log.Printf("Subscribed to resource: uri=%s, command=%s", uri, command)
+	return nil
+}
+
+func (s *Server) unsubscribe(session, uri string) {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	w, ok := s.watches[uri]
+	if !ok {
+		return
+	}
+	delete(w.sessions, session)
+	if len(w.sessions) == 0 {
+		w.cancel()
+		delete(s.watches, uri)
+		log.Printf("Stopped watching resource: uri=%s", uri)
+	}
+}
+
+func (s *Server) unsubscribeSession(session string) {
+	s.watchMu.Lock()
+	var uris []string
+	for uri, w := range s.watches {
+		if w.sessions[session] {
+			uris = append(uris, uri)
+		}
+	}
+	s.watchMu.Unlock()
+
+	for _, uri := range uris {
+		s.unsubscribe(session, uri)
+	}
+}
+
+func (s *Server) stopWatches() {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	for uri, w := range s.watches {
+		w.cancel()
+		delete(s.watches, uri)
+	}
+}
+
+// runWatch notifies subscribers each time the watch prints an object, and
+// restarts the watch with backoff if kubectl exits before ctx is cancelled.
+func (s *Server) runWatch(ctx context.Context, uri, command string) {
+	delay := s.watchRestartDelay
+	failures := 0
+	for {
+		updated := false
+		cmd, stdout, err := kubectl.StartKubectlCommand(ctx, command, s.workDir, s.kubectlConfig)
+		if err == nil {
+			decoder := json.NewDecoder(stdout)
+			for {
+				var event json.RawMessage
+				if decoder.Decode(&event) != nil {
+					break
+				}
+				updated = true
+				s.notifyUpdated(uri)
+			}
+			err = cmd.Wait()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if updated {
+			delay, failures = s.watchRestartDelay, 0
+		}
+		failures++
+		if failures >= maxWatchFailures {
+			s.sessionLog(s.stopWatch(uri), mcp.LoggingLevelError, "Watch failed repeatedly; subscription ended", map[string]any{
+				"uri":      uri,
+				"error":    fmt.Sprint(err),
+				"failures": failures,
+			})
+			return
+		}
+		s.sessionLog(s.watchSessions(uri), mcp.LoggingLevelWarning, "Watch exited; restarting", map[string]any{
+			"uri":   uri,
+			"error": fmt.Sprint(err),
+			"delay": delay.String(),
+		})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxWatchRestartDelay)
+	}
+}
+
+// stopWatch removes the watch of uri and returns the sessions that were
+// subscribed to it.
+func (s *Server) stopWatch(uri string) []string {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	w, ok := s.watches[uri]
+	if !ok {
+		return nil
+	}
+	w.cancel()
+	delete(s.watches, uri)
+	var sessions []string
+	for session := range w.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+func (s *Server) notifyUpdated(uri string) {
+	sessions := s.watchSessions(uri)
+	params := map[string]any{"uri": uri}
+	for _, session := range sessions {
+		if err := s.server.SendNotificationToSpecificClient(session, mcp.MethodNotificationResourceUpdated, params); err != nil {
+			log.Printf("Error notifying session %s of update to %s: %v", session, uri, err)
+		}
+	}
+}
+
+func (s *Server) watchSessions(uri string) []string {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	var sessions []string
+	if w, ok := s.watches[uri]; ok {
+		for session := range w.sessions {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
@@ -0,0 +1,266 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"kubectl-go-mcp-server/internal/config"
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+// contextPollInterval is how often the current kubeconfig context is checked,
+// so that the tool list follows the permissions of a newly selected context.
+const contextPollInterval = 30 * time.Second
+
+// ApplyConfig replaces the server config, e.g. after it was reloaded from
+// disk, and updates the tool list, the prompts, the kubectl environment and
+// the cache and output TTLs. Clients are sent tools/list_changed. An invalid
+// config is rejected and the current one kept.
+//
+// kubeconfig.path, kubectl.path and logging.file are read once at startup;
+// a change to them is logged and takes effect after a restart.
+func (s *Server) ApplyConfig(cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	prompts, err := s.serverPrompts(cfg.Prompts.Dir)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	previous := s.config
+	s.config = cfg
+	s.mu.Unlock()
+
+	warnRestartRequired(previous, cfg)
+	kubectl.SetEnv(cfg.Kubectl.Env, cfg.Kubectl.ProxyEnv)
+	s.results.SetTTL(time.Duration(cfg.Cache.TTLSeconds) * time.Second)
+	s.outputs.SetTTL(time.Duration(cfg.Output.PageTTLSeconds) * time.Second)
+	s.server.SetPrompts(prompts...)
+	return s.updateTools()
+}
+
+// warnRestartRequired logs the changed settings that only take effect after
+// a restart.
+func warnRestartRequired(previous, cfg *config.Config) {
+	settings := []struct {
+		key     string
+		changed bool
+	}{
+		{"kubeconfig.path", previous.Kubeconfig.Path != cfg.Kubeconfig.Path},
+		{"kubectl.path", previous.Kubectl.Path != cfg.Kubectl.Path},
+		{"logging.file", previous.Logging.File != cfg.Logging.File},
+	}
+	for _, setting := range settings {
+		if setting.changed {
+			log.Printf("Warning: %s changed; restart the server to apply it", setting.key)
+		}
+	}
+}
+
+// RefreshTools checks the permissions the tools need against the current
+// context with "kubectl auth can-i" and hides the tools whose permissions are
+// denied. Checks that fail, e.g. because the cluster is unreachable, leave the
+// tool listed; the API server still enforces RBAC when it is called.
+func (s *Server) RefreshTools(ctx context.Context) error {
+	denied := make(map[string]bool)
+	for _, tool := range s.availableTools() {
+		checked, ok := tool.(types.PermissionedTool)
+		if !ok {
+			continue
+		}
+		for _, p := range checked.RequiredPermissions() {
+			if s.canI(ctx, p) == "no" {
+				log.Printf("Hiding tool %s: current context may not %s %s%s", tool.Name(), p.Verb, p.Resource, subresourceSuffix(p))
+				denied[tool.Name()] = true
+				break
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.deniedTools = denied
+	s.mu.Unlock()
+	return s.updateTools()
+}
+
+// availableTools returns every tool the server offers, configured from the
+// current config.
+func (s *Server) availableTools() []types.Tool {
+	cfg := s.GetConfig()
+	kubectlTool := &kubectl.KubectlTool{
+		Discovery:    s.discovery,
+		ReadOnly:     cfg.MCP.ReadOnly,
+		OutputLimits: types.OutputLimits{MaxBytes: cfg.Output.MaxBytes, MaxLines: cfg.Output.MaxLines},
+		Results:      s.results,
+	}
+	kubectlTool.MaxProcessBytes = cfg.Output.MaxProcessBytes
+	if cfg.Output.Paginate {
+		kubectlTool.Pages = s.outputs
+	}
+	if normalize := cfg.Output.Normalize; normalize.Enabled {
+		// The field paths were checked when the config was applied.
+		kubectlTool.Normalizer, _ = kubectl.NewNormalizer(normalize.StripFields, normalize.CompactJSON)
+	}
+	tools := []types.Tool{
+		kubectlTool,
+		&kubectl.APIResourcesTool{Discovery: s.discovery},
+		s.explain,
+		&kubectl.ResourceUsageTool{ThresholdPercent: cfg.ResourceUsage.ThresholdPercent},
+		&kubectl.NodeMaintenanceTool{Results: s.results},
+		&kubectl.ScaleWorkloadTool{Discovery: s.discovery, Limits: kubectl.ScaleLimits(cfg.Scale), Results: s.results},
+	}
+	if cfg.Output.Paginate {
+		tools = append(tools, &kubectl.FetchOutputTool{Store: s.outputs})
+	}
+	return tools
+}
+
+// toolEnabled reports whether the config enables tool: it must be listed in
+// tools.enabled if that is set, must not be listed in tools.disabled, and in
+// read-only mode must be read-only.
+func toolEnabled(cfg *config.Config, tool types.Tool) bool {
+	name := tool.Name()
+	if len(cfg.Tools.Enabled) > 0 && !slices.Contains(cfg.Tools.Enabled, name) {
+		return false
+	}
+	if slices.Contains(cfg.Tools.Disabled, name) {
+		return false
+	}
+	if cfg.MCP.ReadOnly {
+		annotations := tool.FunctionDefinition().Annotations
+		return annotations != nil && annotations.ReadOnlyHint
+	}
+	return true
+}
+
+// updateTools registers the enabled tools with the MCP server, replacing the
+// previous list.
+func (s *Server) updateTools() error {
+	cfg := s.GetConfig()
+	s.mu.RLock()
+	denied := s.deniedTools
+	s.mu.RUnlock()
+
+	tools := NewTools()
+	var serverTools []server.ServerTool
+	for _, tool := range s.availableTools() {
+		if !toolEnabled(cfg, tool) || denied[tool.Name()] {
+			continue
+		}
+		mcpTool, err := newMCPTool(tool.FunctionDefinition())
+		if err != nil {
+			return err
+		}
+		tools.RegisterTool(tool)
+		serverTools = append(serverTools, server.ServerTool{Tool: mcpTool, Handler: s.handleToolCall})
+	}
+
+	s.mu.Lock()
+	s.tools = tools
+	s.mu.Unlock()
+	s.server.SetTools(serverTools...)
+	return nil
+}
+
+func newMCPTool(toolDefn *types.FunctionDefinition) (mcp.Tool, error) {
+	toolInputSchema, err := toolDefn.Parameters.ToRawSchema()
+	if err != nil {
+		return mcp.Tool{}, fmt.Errorf("converting tool schema to json.RawMessage: %w", err)
+	}
+
+	mcpTool := mcp.NewToolWithRawSchema(toolDefn.Name, toolDefn.Description, toolInputSchema)
+	if a := toolDefn.Annotations; a != nil {
+		mcpTool.Annotations = mcp.ToolAnnotation{
+			Title:           a.Title,
+			ReadOnlyHint:    mcp.ToBoolPtr(a.ReadOnlyHint),
+			DestructiveHint: mcp.ToBoolPtr(a.DestructiveHint && !a.ReadOnlyHint),
+			IdempotentHint:  mcp.ToBoolPtr(a.IdempotentHint),
+			OpenWorldHint:   mcp.ToBoolPtr(a.OpenWorldHint),
+		}
+	}
+	if toolDefn.OutputSchema != nil {
+		toolOutputSchema, err := toolDefn.OutputSchema.ToRawSchema()
+		if err != nil {
+			return mcp.Tool{}, fmt.Errorf("converting tool output schema to json.RawMessage: %w", err)
+		}
+		mcpTool.RawOutputSchema = toolOutputSchema
+	}
+	return mcpTool, nil
+}
+
+func subresourceSuffix(p types.Permission) string {
+	if p.Subresource == "" {
+		return ""
+	}
+	return " (subresource " + p.Subresource + ")"
+}
+
+// canI returns "yes", "no", or "" when the check could not be made.
+func (s *Server) canI(ctx context.Context, p types.Permission) string {
+	command := fmt.Sprintf("kubectl auth can-i %s %s", p.Verb, p.Resource)
+	if p.Subresource != "" {
+		command += " --subresource=" + p.Subresource
+	}
+	result, err := kubectl.RunKubectlCommand(ctx, command, s.workDir, s.kubectlConfig)
+	if err != nil {
+		log.Printf("Permission check failed: command=%s, error=%v", command, err)
+		return ""
+	}
+	// can-i prints "no" and exits 1 when the action is denied.
+	switch answer := strings.TrimSpace(result.Stdout); answer {
+	case "yes", "no":
+		return answer
+	default:
+		log.Printf("Permission check failed: command=%s, error=%s, output=%s", command, result.Error, answer)
+		return ""
+	}
+}
+
+// watchContext refreshes the tools on start, and the tools and the API
+// discovery whenever the kubeconfig's current context changes, until ctx is
+// cancelled.
+func (s *Server) watchContext(ctx context.Context) {
+	ticker := time.NewTicker(contextPollInterval)
+	defer ticker.Stop()
+
+	current, checked := "", false
+	for {
+		name := s.currentContext(ctx)
+		if !checked || name != current {
+			if checked {
+				log.Printf("Current context changed from %q to %q; refreshing tools and API discovery", current, name)
+				s.results.Clear()
+				s.discovery.Reset()
+				if err := s.discovery.Refresh(ctx, s.workDir, s.kubectlConfig); err != nil {
+					log.Printf("API discovery failed: %v", err)
+				}
+			}
+			current, checked = name, true
+			if err := s.RefreshTools(ctx); err != nil {
+				log.Printf("Error refreshing tools: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) currentContext(ctx context.Context) string {
+	result, err := kubectl.RunKubectlCommand(ctx, "kubectl config current-context", s.workDir, s.kubectlConfig)
+	if err != nil || result.Error != "" {
+		return ""
+	}
+	return strings.TrimSpace(result.Stdout)
+}
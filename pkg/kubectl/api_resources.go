@@ -0,0 +1,134 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+type APIResourcesTool struct {
+	Discovery *DiscoveryCache
+}
+
+type APIResourcesResult struct {
+	Resources   []APIResource `json:"resources,omitempty"`
+	Count       int           `json:"count"`
+	RefreshedAt time.Time     `json:"refreshed_at,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+func (t *APIResourcesTool) Name() string {
+	return "api_resources"
+}
+
+func (t *APIResourcesTool) Description() string {
+	return `List the resource types served by the cluster (including CRDs) with their short names, API version, scope and supported verbs.
+
+Use "name" to resolve a resource name, kind or short name (e.g. deploy, po) to its canonical resource type.`
+}
+
+func (t *APIResourcesTool) FunctionDefinition() *types.FunctionDefinition {
+	return &types.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &types.Schema{
+			Type: types.TypeObject,
+			Properties: map[string]*types.Schema{
+				"name": {
+					Type:        types.TypeString,
+					Description: `Resource name, kind or short name to resolve, e.g. "deploy", "Pod" or "certificates.cert-manager.io"`,
+				},
+				"verb": {
+					Type:        types.TypeString,
+					Description: `Only list resources supporting this API verb, e.g. "list" or "delete"`,
+				},
+				"scope": {
+					Type:        types.TypeString,
+					Description: `Only list "namespaced" or "cluster" scoped resources`,
+					Enum:        []any{"namespaced", "cluster"},
+				},
+				"refresh": {
+					Type:        types.TypeBoolean,
+					Description: "Re-run discovery against the cluster instead of using the cached results",
+				},
+			},
+		},
+		Annotations: &types.ToolAnnotations{
+			Title:          "List API resources",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  true,
+		},
+		OutputSchema: types.SchemaFor(&APIResourcesResult{}),
+	}
+}
+
+func (t *APIResourcesTool) Run(ctx context.Context, args map[string]any) (any, error) {
+	kubeconfig, workDir, err := contextPaths(ctx)
+	if err != nil {
+		return &APIResourcesResult{Error: err.Error()}, nil
+	}
+
+	name, err := stringArg(args, "name")
+	if err != nil {
+		return &APIResourcesResult{Error: err.Error()}, nil
+	}
+	verb, err := stringArg(args, "verb")
+	if err != nil {
+		return &APIResourcesResult{Error: err.Error()}, nil
+	}
+	scope, err := stringArg(args, "scope")
+	if err != nil {
+		return &APIResourcesResult{Error: err.Error()}, nil
+	}
+	if scope != "" && scope != "namespaced" && scope != "cluster" {
+		return &APIResourcesResult{Error: fmt.Sprintf("scope must be \"namespaced\" or \"cluster\", got %q", scope)}, nil
+	}
+	refresh, err := boolArg(args, "refresh")
+	if err != nil {
+		return &APIResourcesResult{Error: err.Error()}, nil
+	}
+
+	if refresh {
+		if err := t.Discovery.Refresh(ctx, workDir, kubeconfig); err != nil {
+			return &APIResourcesResult{Error: err.Error()}, nil
+		}
+	}
+	resources, err := t.Discovery.Resources(ctx, workDir, kubeconfig)
+	if err != nil && name == "" {
+		return &APIResourcesResult{Error: err.Error()}, nil
+	}
+
+	// Resolving a single name still works from the built-in aliases when
+	// the cluster cannot be reached.
+	if name != "" {
+		resource, ok := t.Discovery.Resolve(name)
+		if !ok {
+			return &APIResourcesResult{Error: fmt.Sprintf("unknown resource type %q", name)}, nil
+		}
+		resources = []APIResource{resource}
+	}
+
+	result := &APIResourcesResult{RefreshedAt: t.Discovery.RefreshedAt()}
+	for _, r := range resources {
+		if verb != "" && !r.SupportsVerb(verb) {
+			continue
+		}
+		if scope == "namespaced" && !r.Namespaced || scope == "cluster" && r.Namespaced {
+			continue
+		}
+		result.Resources = append(result.Resources, r)
+	}
+	result.Count = len(result.Resources)
+	return result, nil
+}
+
+func (t *APIResourcesTool) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *APIResourcesTool) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
@@ -0,0 +1,85 @@
+package kubectl
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+)
+
+func stringArg(args map[string]any, key string) (string, error) {
+	val, ok := args[key]
+	if !ok || val == nil {
+		return "", nil
+	}
+	s, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return s, nil
+}
+
+func boolArg(args map[string]any, key string) (bool, error) {
+	val, ok := args[key]
+	if !ok || val == nil {
+		return false, nil
+	}
+	b, ok := val.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+	return b, nil
+}
+
+// intArg reads an integer argument. JSON numbers arrive as float64, so whole
+// float values are accepted as well.
+func intArg(args map[string]any, key string) (int, bool, error) {
+	val, ok := args[key]
+	if !ok || val == nil {
+		return 0, false, nil
+	}
+	switch v := val.(type) {
+	case int:
+		return v, true, nil
+	case int64:
+		return int(v), true, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, false, fmt.Errorf("%s must be an integer", key)
+		}
+		return int(v), true, nil
+	default:
+		return 0, false, fmt.Errorf("%s must be an integer", key)
+	}
+}
+
+var (
+	namespacePattern  = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	objectNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+	selectorPattern   = regexp.MustCompile(`^[A-Za-z0-9_./=!,-]+$`)
+)
+
+func validateNamespace(namespace string) error {
+	if namespace != "" && (len(namespace) > 63 || !namespacePattern.MatchString(namespace)) {
+		return fmt.Errorf("invalid namespace %q", namespace)
+	}
+	return nil
+}
+
+func validateObjectName(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s name is required", kind)
+	}
+	if len(name) > 253 || !objectNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid %s name %q", kind, name)
+	}
+	return nil
+}
+
+// validateSelector accepts equality-based label selectors only; set-based
+// selectors need characters the shell would interpret.
+func validateSelector(selector string) error {
+	if selector != "" && !selectorPattern.MatchString(selector) {
+		return fmt.Errorf("invalid label selector %q: only equality-based selectors such as app=web,tier!=db are supported", selector)
+	}
+	return nil
+}
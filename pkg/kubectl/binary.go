@@ -0,0 +1,103 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Binary is a kubectl executable resolved at startup.
+type Binary struct {
+	Path          string
+	ClientVersion *VersionInfo
+}
+
+// pinned holds the path of the pinned kubectl, which kubectlCmd runs in
+// place of the kubectl a command names.
+var pinned struct {
+	sync.RWMutex
+	path string
+}
+
+// ResolveBinary finds kubectl at path, or on PATH if path is empty, checks
+// that it is an executable file and reads its client version.
+func ResolveBinary(ctx context.Context, path string) (*Binary, error) {
+	if path == "" {
+		found, err := exec.LookPath("kubectl")
+		if err != nil {
+			return nil, fmt.Errorf("kubectl not found on PATH; install it or set kubectl.path in the config")
+		}
+		path = found
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("kubectl path %s: %w", path, err)
+	}
+	if !info.Mode().IsRegular() || (runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0) {
+		return nil, fmt.Errorf("kubectl path %s is not an executable file", path)
+	}
+
+	cmd := exec.CommandContext(ctx, path, "version", "--client", "-o", "json")
+	cmd.Env = ChildEnv()
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("running %s version: %w", path, err)
+	}
+	version, err := ParseKubectlVersion(string(output))
+	if err != nil {
+		return nil, err
+	}
+	if version.ClientVersion == nil || version.ClientVersion.GitVersion == "" {
+		return nil, fmt.Errorf("%s reported no client version", path)
+	}
+	return &Binary{Path: path, ClientVersion: version.ClientVersion}, nil
+}
+
+// PinBinary makes commands run binary rather than the first kubectl on PATH.
+// A nil binary undoes it.
+func PinBinary(binary *Binary) {
+	pinned.Lock()
+	defer pinned.Unlock()
+	pinned.path = ""
+	if binary != nil {
+		pinned.path = binary.Path
+	}
+}
+
+// withPinnedBinary returns command with its first word, kubectl, replaced
+// by the pinned kubectl's path.
+func withPinnedBinary(command string) string {
+	pinned.RLock()
+	path := pinned.path
+	pinned.RUnlock()
+	if path == "" {
+		return command
+	}
+	command = strings.TrimLeft(command, " \t")
+	end := strings.IndexAny(command, " \t")
+	if end < 0 {
+		end = len(command)
+	}
+	return shellQuote(path) + command[end:]
+}
+
+// shellQuote quotes s as a single word for the shell that runs commands.
+func shellQuote(s string) string {
+	if strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789/._-+:\\") == "" {
+		return s
+	}
+	if runtime.GOOS == "windows" {
+		return `"` + s + `"`
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,365 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultDiscoveryRefreshInterval = 5 * time.Minute
+
+type APIResource struct {
+	Name       string   `json:"name"`
+	ShortNames []string `json:"short_names,omitempty"`
+	APIVersion string   `json:"api_version"`
+	Namespaced bool     `json:"namespaced"`
+	Kind       string   `json:"kind"`
+	Verbs      []string `json:"verbs,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+}
+
+func (r APIResource) Group() string {
+	if i := strings.Index(r.APIVersion, "/"); i >= 0 {
+		return r.APIVersion[:i]
+	}
+	return ""
+}
+
+func (r APIResource) SupportsVerb(verb string) bool {
+	for _, v := range r.Verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// builtinResources is used to resolve well-known aliases before the cluster
+// has been queried (or when it cannot be reached). Verbs are left empty so
+// that no verb checks are made against this fallback.
+var builtinResources = []APIResource{
+	{Name: "pods", ShortNames: []string{"po"}, APIVersion: "v1", Namespaced: true, Kind: "Pod"},
+	{Name: "services", ShortNames: []string{"svc"}, APIVersion: "v1", Namespaced: true, Kind: "Service"},
+	{Name: "namespaces", ShortNames: []string{"ns"}, APIVersion: "v1", Kind: "Namespace"},
+	{Name: "nodes", ShortNames: []string{"no"}, APIVersion: "v1", Kind: "Node"},
+	{Name: "configmaps", ShortNames: []string{"cm"}, APIVersion: "v1", Namespaced: true, Kind: "ConfigMap"},
+	{Name: "secrets", APIVersion: "v1", Namespaced: true, Kind: "Secret"},
+	{Name: "serviceaccounts", ShortNames: []string{"sa"}, APIVersion: "v1", Namespaced: true, Kind: "ServiceAccount"},
+	{Name: "endpoints", ShortNames: []string{"ep"}, APIVersion: "v1", Namespaced: true, Kind: "Endpoints"},
+	{Name: "events", ShortNames: []string{"ev"}, APIVersion: "v1", Namespaced: true, Kind: "Event"},
+	{Name: "persistentvolumes", ShortNames: []string{"pv"}, APIVersion: "v1", Kind: "PersistentVolume"},
+	{Name: "persistentvolumeclaims", ShortNames: []string{"pvc"}, APIVersion: "v1", Namespaced: true, Kind: "PersistentVolumeClaim"},
+	{Name: "replicationcontrollers", ShortNames: []string{"rc"}, APIVersion: "v1", Namespaced: true, Kind: "ReplicationController"},
+	{Name: "resourcequotas", ShortNames: []string{"quota"}, APIVersion: "v1", Namespaced: true, Kind: "ResourceQuota"},
+	{Name: "limitranges", ShortNames: []string{"limits"}, APIVersion: "v1", Namespaced: true, Kind: "LimitRange"},
+	{Name: "deployments", ShortNames: []string{"deploy"}, APIVersion: "apps/v1", Namespaced: true, Kind: "Deployment"},
+	{Name: "daemonsets", ShortNames: []string{"ds"}, APIVersion: "apps/v1", Namespaced: true, Kind: "DaemonSet"},
+	{Name: "statefulsets", ShortNames: []string{"sts"}, APIVersion: "apps/v1", Namespaced: true, Kind: "StatefulSet"},
+	{Name: "replicasets", ShortNames: []string{"rs"}, APIVersion: "apps/v1", Namespaced: true, Kind: "ReplicaSet"},
+	{Name: "jobs", APIVersion: "batch/v1", Namespaced: true, Kind: "Job"},
+	{Name: "cronjobs", ShortNames: []string{"cj"}, APIVersion: "batch/v1", Namespaced: true, Kind: "CronJob"},
+	{Name: "horizontalpodautoscalers", ShortNames: []string{"hpa"}, APIVersion: "autoscaling/v2", Namespaced: true, Kind: "HorizontalPodAutoscaler"},
+	{Name: "ingresses", ShortNames: []string{"ing"}, APIVersion: "networking.k8s.io/v1", Namespaced: true, Kind: "Ingress"},
+	{Name: "networkpolicies", ShortNames: []string{"netpol"}, APIVersion: "networking.k8s.io/v1", Namespaced: true, Kind: "NetworkPolicy"},
+	{Name: "poddisruptionbudgets", ShortNames: []string{"pdb"}, APIVersion: "policy/v1", Namespaced: true, Kind: "PodDisruptionBudget"},
+	{Name: "storageclasses", ShortNames: []string{"sc"}, APIVersion: "storage.k8s.io/v1", Kind: "StorageClass"},
+	{Name: "customresourcedefinitions", ShortNames: []string{"crd", "crds"}, APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition"},
+	{Name: "roles", APIVersion: "rbac.authorization.k8s.io/v1", Namespaced: true, Kind: "Role"},
+	{Name: "rolebindings", APIVersion: "rbac.authorization.k8s.io/v1", Namespaced: true, Kind: "RoleBinding"},
+	{Name: "clusterroles", APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+	{Name: "clusterrolebindings", APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+}
+
+// BuiltinResources returns the well-known resources used before discovery
+// has succeeded.
+func BuiltinResources() []APIResource {
+	resources := make([]APIResource, len(builtinResources))
+	copy(resources, builtinResources)
+	return resources
+}
+
+// requiredAPIVerbs maps kubectl subcommands to the API verbs a resource must
+// support (any one of them) for the subcommand to make sense.
+var requiredAPIVerbs = map[string][]string{
+	"get":      {"get", "list"},
+	"describe": {"get", "list"},
+	"delete":   {"delete", "deletecollection"},
+	"patch":    {"patch"},
+	"label":    {"patch"},
+	"annotate": {"patch"},
+	"replace":  {"update"},
+}
+
+// flagsWithValue lists short and long flags that consume the following word
+// when written without '='.
+var flagsWithValue = map[string]bool{
+	"-n": true, "--namespace": true,
+	"-l": true, "--selector": true,
+	"-o": true, "--output": true,
+	"-f": true, "--filename": true,
+	"-c": true, "--container": true,
+	"-L": true, "--label-columns": true,
+	"-p": true, "--patch": true,
+	"--context": true, "--cluster": true, "--user": true, "--kubeconfig": true,
+	"--field-selector": true, "--sort-by": true, "--template": true,
+}
+
+type DiscoveryCache struct {
+	mu          sync.RWMutex
+	resources   []APIResource
+	aliases     map[string]int
+	refreshedAt time.Time
+	interval    time.Duration
+}
+
+func NewDiscoveryCache(interval time.Duration) *DiscoveryCache {
+	if interval <= 0 {
+		interval = DefaultDiscoveryRefreshInterval
+	}
+	return &DiscoveryCache{interval: interval}
+}
+
+func (d *DiscoveryCache) Refresh(ctx context.Context, workDir, kubeconfig string) error {
+	result, err := RunKubectlCommand(ctx, "kubectl api-resources -o wide", workDir, kubeconfig)
+	if err != nil {
+		return fmt.Errorf("running kubectl api-resources: %w", err)
+	}
+	if result.Error != "" {
+		return fmt.Errorf("running kubectl api-resources: %s: %s", result.Error, strings.TrimSpace(result.Stdout))
+	}
+
+	resources, err := ParseAPIResources(result.Stdout)
+	if err != nil {
+		return err
+	}
+	d.Update(resources)
+	return nil
+}
+
+func (d *DiscoveryCache) Update(resources []APIResource) {
+	aliases := make(map[string]int)
+	for i, r := range resources {
+		names := append([]string{r.Name, strings.ToLower(r.Kind)}, r.ShortNames...)
+		if group := r.Group(); group != "" {
+			names = append(names, r.Name+"."+group, strings.ToLower(r.Kind)+"."+group)
+		}
+		for _, name := range names {
+			// The first resource wins so that core kinds are preferred over
+			// CRDs that reuse a short name further down the list.
+			if _, exists := aliases[name]; !exists && name != "" {
+				aliases[name] = i
+			}
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resources = resources
+	d.aliases = aliases
+	d.refreshedAt = time.Now()
+}
+
+// Reset drops the discovered resources, e.g. when the current context
+// changes, so that the built-in aliases are used until the next refresh.
+func (d *DiscoveryCache) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resources = nil
+	d.aliases = nil
+	d.refreshedAt = time.Time{}
+}
+
+func (d *DiscoveryCache) Stale() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.resources == nil || time.Since(d.refreshedAt) > d.interval
+}
+
+func (d *DiscoveryCache) RefreshedAt() time.Time {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.refreshedAt
+}
+
+// Resources returns the discovered resources, refreshing them first when the
+// cache is empty or older than the refresh interval.
+func (d *DiscoveryCache) Resources(ctx context.Context, workDir, kubeconfig string) ([]APIResource, error) {
+	if d.Stale() {
+		if err := d.Refresh(ctx, workDir, kubeconfig); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	resources := make([]APIResource, len(d.resources))
+	copy(resources, d.resources)
+	return resources, nil
+}
+
+// Resolve maps a resource name, kind, short name or "name.group" form to the
+// canonical resource. Well-known built-in aliases are used as a fallback when
+// the cluster has not been discovered yet.
+func (d *DiscoveryCache) Resolve(name string) (APIResource, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return APIResource{}, false
+	}
+
+	if d != nil {
+		d.mu.RLock()
+		i, ok := d.aliases[name]
+		if ok {
+			r := d.resources[i]
+			d.mu.RUnlock()
+			return r, true
+		}
+		d.mu.RUnlock()
+	}
+
+	for _, r := range builtinResources {
+		if r.Name == name || strings.ToLower(r.Kind) == name || r.Name+"."+r.Group() == name {
+			return r, true
+		}
+		for _, short := range r.ShortNames {
+			if short == name {
+				return r, true
+			}
+		}
+	}
+	return APIResource{}, false
+}
+
+// ResourceForCommand resolves the resource type a kubectl command operates on,
+// e.g. "deploy" in "kubectl get deploy my-app".
+func (d *DiscoveryCache) ResourceForCommand(command string) (APIResource, bool) {
+	arg := CommandResourceArg(command)
+	if arg == "" {
+		return APIResource{}, false
+	}
+	return d.Resolve(arg)
+}
+
+// CheckCommand rejects commands whose verb is not supported by the resource
+// they target, according to the verbs the cluster advertised. Resources that
+// cannot be resolved are left for kubectl to report on.
+func (d *DiscoveryCache) CheckCommand(command string) error {
+	words := strings.Fields(command)
+	if len(words) < 2 {
+		return nil
+	}
+	required, ok := requiredAPIVerbs[words[1]]
+	if !ok {
+		return nil
+	}
+
+	resource, ok := d.ResourceForCommand(command)
+	if !ok || len(resource.Verbs) == 0 {
+		return nil
+	}
+	for _, verb := range required {
+		if resource.SupportsVerb(verb) {
+			return nil
+		}
+	}
+	return fmt.Errorf("resource %s does not support %s (supported verbs: %s)",
+		resource.Name, words[1], strings.Join(resource.Verbs, ", "))
+}
+
+// Run refreshes the cache every interval until ctx is cancelled.
+func (d *DiscoveryCache) Run(ctx context.Context, workDir, kubeconfig string) {
+	if err := d.Refresh(ctx, workDir, kubeconfig); err != nil {
+		log.Printf("API discovery failed: %v", err)
+	}
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Refresh(ctx, workDir, kubeconfig); err != nil {
+				log.Printf("API discovery refresh failed: %v", err)
+			}
+		}
+	}
+}
+
+// CommandResourceArg returns the first positional argument after the kubectl
+// subcommand with any "/name" suffix and additional comma-separated types
+// removed, or "" when there is none.
+func CommandResourceArg(command string) string {
+	words := strings.Fields(command)
+	if len(words) < 3 || filepath.Base(words[0]) != "kubectl" {
+		return ""
+	}
+
+	for i := 2; i < len(words); i++ {
+		word := words[i]
+		if word == "--" {
+			return ""
+		}
+		if strings.HasPrefix(word, "-") {
+			if !strings.Contains(word, "=") && flagsWithValue[word] {
+				i++
+			}
+			continue
+		}
+		if j := strings.Index(word, "/"); j >= 0 {
+			word = word[:j]
+		}
+		if j := strings.Index(word, ","); j >= 0 {
+			word = word[:j]
+		}
+		return word
+	}
+	return ""
+}
+
+// ParseAPIResources parses the table printed by "kubectl api-resources -o wide".
+// Columns are located by their header offsets since SHORTNAMES may be empty.
+func ParseAPIResources(output string) ([]APIResource, error) {
+	if !strings.HasPrefix(strings.TrimSpace(output), "NAME") {
+		return nil, fmt.Errorf("unexpected api-resources output: missing header")
+	}
+	blocks, err := parseTableBlocks(output)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected api-resources output: %w", err)
+	}
+
+	var resources []APIResource
+	for _, block := range blocks {
+		for i, fields := range block.table.Rows {
+			r := APIResource{
+				Name:       fields["NAME"],
+				APIVersion: fields["APIVERSION"],
+				Namespaced: fields["NAMESPACED"] == "true",
+				Kind:       fields["KIND"],
+				ShortNames: splitList(fields["SHORTNAMES"]),
+				Verbs:      splitList(fields["VERBS"]),
+				Categories: splitList(fields["CATEGORIES"]),
+			}
+			if r.Name == "" || r.Kind == "" {
+				return nil, fmt.Errorf("unexpected api-resources row: %q", block.lines[i])
+			}
+			resources = append(resources, r)
+		}
+	}
+	return resources, nil
+}
+
+func splitList(value string) []string {
+	value = strings.Trim(value, "[]")
+	items := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(items) == 0 {
+		return nil
+	}
+	return items
+}
@@ -0,0 +1,106 @@
+package kubectl
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// DefaultEnv names the environment variables passed to kubectl when no
+// allowlist is set: what kubectl itself needs and what the common cloud
+// credential plugins read. A name ending in "*" matches every variable with
+// that prefix.
+var DefaultEnv = []string{
+	"PATH", "HOME", "USER", "LANG", "LC_ALL", "TMPDIR",
+	// Windows
+	"SYSTEMROOT", "COMSPEC", "USERPROFILE", "APPDATA", "LOCALAPPDATA", "TEMP", "TMP",
+	// EKS: aws eks get-token, aws-iam-authenticator
+	"AWS_*",
+	// GKE: gke-gcloud-auth-plugin
+	"CLOUDSDK_*", "GOOGLE_APPLICATION_CREDENTIALS", "USE_GKE_GCLOUD_AUTH_PLUGIN",
+	// AKS: kubelogin
+	"AZURE_*", "AAD_*",
+}
+
+// ProxyEnv names the proxy variables passed to kubectl when enabled.
+var ProxyEnv = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
+var childEnv struct {
+	sync.RWMutex
+	allowlist []string
+}
+
+// SetEnv sets the environment variables passed to kubectl: those allowlist
+// names, or DefaultEnv if it is nil, and ProxyEnv if proxy is set.
+func SetEnv(allowlist []string, proxy bool) {
+	if allowlist == nil {
+		allowlist = DefaultEnv
+	}
+	allowlist = append([]string(nil), allowlist...)
+	if proxy {
+		allowlist = append(allowlist, ProxyEnv...)
+	}
+
+	childEnv.Lock()
+	defer childEnv.Unlock()
+	childEnv.allowlist = allowlist
+}
+
+// ChildEnv returns the allowed variables of the server's environment, which
+// kubectl commands run with. KUBECONFIG is never passed on; each command
+// sets its own.
+func ChildEnv() []string {
+	childEnv.RLock()
+	allowlist := childEnv.allowlist
+	childEnv.RUnlock()
+	if allowlist == nil {
+		allowlist = DefaultEnv
+	}
+
+	var env []string
+	for _, envVar := range os.Environ() {
+		name, _, _ := strings.Cut(envVar, "=")
+		if name == "KUBECONFIG" {
+			continue
+		}
+		for _, pattern := range allowlist {
+			if envNameMatches(pattern, name) {
+				env = append(env, envVar)
+				break
+			}
+		}
+	}
+	return env
+}
+
+func envNameMatches(pattern, name string) bool {
+	// Variable names are case-insensitive on Windows.
+	if runtime.GOOS == "windows" {
+		pattern, name = strings.ToUpper(pattern), strings.ToUpper(name)
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return pattern == name
+}
+
+// secretEnvNames are parts of variable names whose values DescribeEnv hides.
+var secretEnvNames = []string{"SECRET", "TOKEN", "PASSWORD", "KEY", "CREDENTIAL"}
+
+// DescribeEnv renders env for logs, with the values of variables that look
+// like credentials masked.
+func DescribeEnv(env []string) string {
+	described := make([]string, 0, len(env))
+	for _, envVar := range env {
+		name, value, _ := strings.Cut(envVar, "=")
+		for _, secret := range secretEnvNames {
+			if strings.Contains(strings.ToUpper(name), secret) {
+				value = maskedValue
+				break
+			}
+		}
+		described = append(described, name+"="+value)
+	}
+	return strings.Join(described, " ")
+}
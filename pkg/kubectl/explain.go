@@ -0,0 +1,210 @@
+package kubectl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+const maxExplainCacheEntries = 512
+
+var (
+	explainKindPattern       = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+	explainFieldPattern      = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+	explainAPIVersionPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*(/[a-z0-9]+)?$`)
+)
+
+type ExplainTool struct {
+	Discovery *DiscoveryCache
+
+	mu       sync.Mutex
+	cache    map[string]string
+	versions serverVersions
+}
+
+type ExplainResult struct {
+	Resource      string `json:"resource"`
+	Field         string `json:"field,omitempty"`
+	Recursive     bool   `json:"recursive,omitempty"`
+	ServerVersion string `json:"server_version,omitempty"`
+	Documentation string `json:"documentation,omitempty"`
+	Cached        bool   `json:"cached,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+func (t *ExplainTool) Name() string {
+	return "explain"
+}
+
+func (t *ExplainTool) Description() string {
+	return `Look up the schema documentation for a resource kind or one of its fields, as served by the cluster (including CRDs).
+
+Use this before writing or editing manifests to check field names, types and nesting instead of guessing.
+
+Examples: kind "deployment" with field "spec.strategy", kind "pod" with field "spec.containers.resources" and recursive true`
+}
+
+func (t *ExplainTool) FunctionDefinition() *types.FunctionDefinition {
+	return &types.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &types.Schema{
+			Type: types.TypeObject,
+			Properties: map[string]*types.Schema{
+				"kind": {
+					Type:        types.TypeString,
+					Description: `Resource kind, name or short name, e.g. "Deployment", "pods", "deploy" or "certificates.cert-manager.io"`,
+				},
+				"field": {
+					Type:        types.TypeString,
+					Description: `Dotted field path below the kind, e.g. "spec.template.spec.containers.livenessProbe"`,
+				},
+				"recursive": {
+					Type:        types.TypeBoolean,
+					Description: "Print all nested fields instead of only the direct children",
+				},
+				"api_version": {
+					Type:        types.TypeString,
+					Description: `API version to explain when the kind is served in several, e.g. "autoscaling/v2"`,
+				},
+			},
+			Required: []string{"kind"},
+		},
+		Annotations: &types.ToolAnnotations{
+			Title:          "Explain resource fields",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  true,
+		},
+		OutputSchema: types.SchemaFor(&ExplainResult{}),
+	}
+}
+
+func (t *ExplainTool) Run(ctx context.Context, args map[string]any) (any, error) {
+	kubeconfig, workDir, err := contextPaths(ctx)
+	if err != nil {
+		return &ExplainResult{Error: err.Error()}, nil
+	}
+
+	kind, err := stringArg(args, "kind")
+	if err != nil {
+		return &ExplainResult{Error: err.Error()}, nil
+	}
+	field, err := stringArg(args, "field")
+	if err != nil {
+		return &ExplainResult{Error: err.Error()}, nil
+	}
+	recursive, err := boolArg(args, "recursive")
+	if err != nil {
+		return &ExplainResult{Error: err.Error()}, nil
+	}
+	apiVersion, err := stringArg(args, "api_version")
+	if err != nil {
+		return &ExplainResult{Error: err.Error()}, nil
+	}
+
+	result := &ExplainResult{Resource: kind, Field: strings.Trim(field, "."), Recursive: recursive}
+	if resource, ok := t.Discovery.Resolve(kind); ok {
+		result.Resource = resource.Name
+		// kubectl explain reads "a.b" as resource a, field b, so group
+		// qualified names are passed as resource plus --api-version.
+		if strings.Contains(kind, ".") {
+			kind = resource.Name
+			if apiVersion == "" {
+				apiVersion = resource.APIVersion
+			}
+		}
+	}
+
+	command, err := explainCommand(kind, field, apiVersion, recursive)
+	if err != nil {
+		return &ExplainResult{Error: err.Error()}, nil
+	}
+
+	// Without a known cluster version results are not cached, since the
+	// schema they describe may change underneath us. Clusters of the same
+	// version can still serve different CRDs, so the context is part of the
+	// key.
+	version, versionErr := t.versions.Get(ctx, workDir, kubeconfig)
+	key := strings.Join([]string{kubeconfig, currentContext(kubeconfig), version, command}, "|")
+	if versionErr == nil {
+		result.ServerVersion = version
+		if doc, ok := t.lookup(key); ok {
+			result.Documentation = doc
+			result.Cached = true
+			return result, nil
+		}
+	}
+
+	execResult, err := RunKubectlCommand(ctx, command, workDir, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if execResult.Error != "" {
+		result.Error = fmt.Sprintf("%s: %s", execResult.Error, strings.TrimSpace(execResult.Stdout))
+		return result, nil
+	}
+
+	result.Documentation = execResult.Stdout
+	if versionErr == nil {
+		t.store(key, execResult.Stdout)
+	}
+	return result, nil
+}
+
+func (t *ExplainTool) lookup(key string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	doc, ok := t.cache[key]
+	return doc, ok
+}
+
+func (t *ExplainTool) store(key, doc string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cache == nil || len(t.cache) >= maxExplainCacheEntries {
+		t.cache = make(map[string]string)
+	}
+	t.cache[key] = doc
+}
+
+func (t *ExplainTool) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *ExplainTool) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
+
+func explainCommand(kind, field, apiVersion string, recursive bool) (string, error) {
+	if kind == "" {
+		return "", fmt.Errorf("kind is required")
+	}
+	if !explainKindPattern.MatchString(kind) {
+		return "", fmt.Errorf("invalid kind %q: use api_version to select a group", kind)
+	}
+
+	target := kind
+	if field = strings.Trim(field, "."); field != "" {
+		if !explainFieldPattern.MatchString(field) {
+			return "", fmt.Errorf("invalid field path %q: expected dotted field names such as spec.replicas", field)
+		}
+		target += "." + field
+	}
+
+	command := "kubectl explain " + target
+	if recursive {
+		command += " --recursive"
+	}
+	if apiVersion != "" {
+		if !explainAPIVersionPattern.MatchString(apiVersion) {
+			return "", fmt.Errorf("invalid api_version %q", apiVersion)
+		}
+		command += " --api-version=" + apiVersion
+	}
+	return command, nil
+}
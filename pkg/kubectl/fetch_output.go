@@ -0,0 +1,76 @@
+package kubectl
+
+import (
+	"context"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+// FetchOutputTool returns further pages of kubectl output that was too large
+// to return at once.
+type FetchOutputTool struct {
+	Store *OutputStore
+}
+
+type FetchOutputResult struct {
+	Output     string `json:"output"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Offset     int64  `json:"offset"`
+	TotalBytes int64  `json:"total_bytes"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (t *FetchOutputTool) Name() string {
+	return "fetch_output"
+}
+
+func (t *FetchOutputTool) Description() string {
+	return `Fetch the next page of kubectl output that was too large to return at once.
+
+Pass the next_cursor of a kubectl result, or of a previous fetch_output result. The result has a next_cursor while more output remains. Stored output expires after a few minutes; run the command again if the cursor has expired.`
+}
+
+func (t *FetchOutputTool) FunctionDefinition() *types.FunctionDefinition {
+	return &types.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &types.Schema{
+			Type: types.TypeObject,
+			Properties: map[string]*types.Schema{
+				"cursor": {
+					Type:        types.TypeString,
+					Description: "The next_cursor value of the previous page",
+				},
+			},
+			Required: []string{"cursor"},
+		},
+		Annotations: &types.ToolAnnotations{
+			Title:          "Fetch more output",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+		},
+		OutputSchema: types.SchemaFor(&FetchOutputResult{}),
+	}
+}
+
+func (t *FetchOutputTool) Run(ctx context.Context, args map[string]any) (any, error) {
+	cursor, err := stringArg(args, "cursor")
+	if err != nil {
+		return &FetchOutputResult{Error: err.Error()}, nil
+	}
+
+	result := &FetchOutputResult{}
+	result.Output, result.NextCursor, result.Offset, result.TotalBytes, err = t.Store.Page(cursor)
+	if err != nil {
+		return &FetchOutputResult{Error: err.Error()}, nil
+	}
+	return result, nil
+}
+
+func (t *FetchOutputTool) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *FetchOutputTool) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
@@ -0,0 +1,163 @@
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/itchyny/gojq"
+)
+
+// Filter extracts values from JSON output with a jq expression, evaluated by
+// gojq:
+//
+//	.items[0].metadata.name
+//	.items[].metadata.name
+//	.items[].metadata.labels["app.kubernetes.io/name"]
+//	.items[] | select(.status.phase != "Running") | {name: .metadata.name, node: .spec.nodeName}
+//	[.items[] | select(.metadata.name | test("^web-"))] | length
+//
+// The filter sees no environment variables and cannot read input other than
+// the command's output.
+type Filter struct {
+	code *gojq.Code
+}
+
+const (
+	// filterTimeout bounds the evaluation of a filter, which can loop.
+	filterTimeout = 10 * time.Second
+	// maxFilterOutputBytes bounds the values a filter may produce.
+	maxFilterOutputBytes = 16 << 20
+)
+
+// ParseFilter parses and compiles a jq expression.
+func ParseFilter(expr string) (*Filter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("filter is empty")
+	}
+	query, err := gojq.Parse(expr)
+	if err == nil {
+		var code *gojq.Code
+		if code, err = gojq.Compile(query); err == nil {
+			return &Filter{code: code}, nil
+		}
+	}
+	if looksLikeJSONPath(expr) {
+		return nil, fmt.Errorf("invalid filter %q: %v; JSONPath is not supported, use jq syntax such as .items[].metadata.name", expr, err)
+	}
+	return nil, fmt.Errorf("invalid filter %q: %v", expr, err)
+}
+
+// looksLikeJSONPath reports whether expr is written as kubectl JSONPath,
+// such as {.items[*].metadata.name}.
+func looksLikeJSONPath(expr string) bool {
+	expr = strings.TrimSpace(expr)
+	return strings.HasPrefix(expr, "{.") || strings.HasPrefix(expr, "$") ||
+		strings.Contains(expr, "[*]") || strings.Contains(expr, "[?(")
+}
+
+// Apply evaluates the filter over a JSON document and returns each value it
+// produces as indented JSON on its own lines, like jq.
+func (f *Filter) Apply(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var input any
+	if err := decoder.Decode(&input); err != nil {
+		return nil, fmt.Errorf("filter failed: output is not JSON: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), filterTimeout)
+	defer cancel()
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	iter := f.code.RunWithContext(ctx, jqValue(input))
+	for {
+		value, ok := iter.Next()
+		if !ok {
+			return out.Bytes(), nil
+		}
+		if err, ok := value.(error); ok {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, fmt.Errorf("filter failed: evaluation took longer than %s", filterTimeout)
+			}
+			return nil, fmt.Errorf("filter failed: %v", err)
+		}
+		if err := encoder.Encode(value); err != nil {
+			return nil, fmt.Errorf("filter failed: %v", err)
+		}
+		if out.Len() > maxFilterOutputBytes {
+			return nil, fmt.Errorf("filter failed: output exceeds %d bytes", maxFilterOutputBytes)
+		}
+	}
+}
+
+// jqValue converts the json.Numbers in v, which gojq would treat as strings,
+// to the int, *big.Int and float64 values it computes with.
+func jqValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		if n, ok := new(big.Int).SetString(v.String(), 10); ok {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, value := range v {
+			v[key] = jqValue(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = jqValue(value)
+		}
+	}
+	return v
+}
+
+// jsonOutputCommands are the read commands that take -o json, by verb or by
+// verb and subcommand.
+var jsonOutputCommands = map[string]bool{
+	"get": true, "version": true, "config view": true,
+}
+
+// printsJSON reports whether a filter can be applied to parsed's output.
+// Other commands reject -o json or, like exec, would pass it on to another
+// program.
+func printsJSON(parsed parsedCommand) bool {
+	if len(parsed.rest) > 0 {
+		return false
+	}
+	if jsonOutputCommands[parsed.verb] {
+		return true
+	}
+	return len(parsed.positional) > 0 && jsonOutputCommands[parsed.verb+" "+parsed.positional[0]]
+}
+
+// outputFormat returns the value of the -o/--output flag in command, or ""
+// if it is not set.
+func outputFormat(command string) string {
+	words := strings.Fields(command)
+	for i, word := range words {
+		if word == "--" {
+			break
+		}
+		switch {
+		case (word == "-o" || word == "--output") && i+1 < len(words):
+			return words[i+1]
+		case strings.HasPrefix(word, "--output="):
+			return strings.TrimPrefix(word, "--output=")
+		case strings.HasPrefix(word, "-o"):
+			return strings.TrimPrefix(strings.TrimPrefix(word, "-o"), "=")
+		}
+	}
+	return ""
+}
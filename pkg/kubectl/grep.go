@@ -0,0 +1,183 @@
+package kubectl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// maxGrepContextLines bounds context_lines.
+const maxGrepContextLines = 50
+
+// LineGrep selects the lines of command output that match, or with Invert do
+// not match, Pattern, like grep -E. Context lines around each match are kept
+// and groups that are not adjacent are separated by "--". MaxMatches, if
+// positive, stops after that many matches.
+type LineGrep struct {
+	Pattern    *regexp.Regexp
+	Invert     bool
+	Context    int
+	MaxMatches int
+}
+
+// Apply returns the selected lines of output.
+func (g *LineGrep) Apply(output []byte) ([]byte, error) {
+	var out bytes.Buffer
+	w := g.writer(&out)
+	w.Write(output)
+	w.flush()
+	return out.Bytes(), nil
+}
+
+// writer returns a writer that passes the selected lines of what is written
+// to it on to next as they complete, so output is searched without keeping
+// it whole.
+func (g *LineGrep) writer(next io.Writer) *grepWriter {
+	return &grepWriter{grep: g, next: next, printed: -1, contextUntil: -1}
+}
+
+type grepWriter struct {
+	grep *LineGrep
+	next io.Writer
+
+	partial []byte
+	// before holds the last unprinted lines, up to Context of them.
+	before       []numberedLine
+	line         int
+	matches      int
+	printed      int
+	contextUntil int
+}
+
+type numberedLine struct {
+	number int
+	text   []byte
+}
+
+func (w *grepWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.partial = append(w.partial, p...)
+			break
+		}
+		if len(w.partial) > 0 {
+			w.partial = append(w.partial, p[:i]...)
+			w.take(w.partial)
+			w.partial = w.partial[:0]
+		} else {
+			w.take(p[:i])
+		}
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// flush takes a final line without newline.
+func (w *grepWriter) flush() {
+	if len(w.partial) > 0 {
+		w.take(w.partial)
+		w.partial = nil
+	}
+}
+
+func (w *grepWriter) take(line []byte) {
+	g, i := w.grep, w.line
+	w.line++
+	if g.MaxMatches > 0 && w.matches >= g.MaxMatches {
+		if i <= w.contextUntil {
+			w.emit(line)
+		}
+		return
+	}
+
+	switch {
+	case g.selects(line):
+		w.matches++
+		start := max(i-g.Context, w.printed+1)
+		if g.Context > 0 && w.printed >= 0 && start > w.printed+1 {
+			io.WriteString(w.next, "--\n")
+		}
+		for _, before := range w.before {
+			if before.number >= start {
+				w.emit(before.text)
+			}
+		}
+		w.before = w.before[:0]
+		w.emit(line)
+		w.printed, w.contextUntil = i, i+g.Context
+	case i <= w.contextUntil:
+		w.emit(line)
+		w.printed = i
+	case g.Context > 0:
+		if len(w.before) == g.Context {
+			w.before = append(w.before[:0], w.before[1:]...)
+		}
+		w.before = append(w.before, numberedLine{number: i, text: append([]byte(nil), line...)})
+	}
+}
+
+func (w *grepWriter) emit(line []byte) {
+	w.next.Write(line)
+	w.next.Write([]byte{'\n'})
+}
+
+func (g *LineGrep) selects(line []byte) bool {
+	return g.Pattern.Match(line) != g.Invert
+}
+
+// tableRows returns the rows of table whose lines grep selects, so that
+// parsed tables agree with the grepped text.
+func (g *LineGrep) tableRows(block tableBlock) []map[string]string {
+	rows := []map[string]string{}
+	for i, line := range block.lines {
+		if g.MaxMatches > 0 && len(rows) >= g.MaxMatches {
+			break
+		}
+		if g.selects([]byte(line)) {
+			rows = append(rows, block.table.Rows[i])
+		}
+	}
+	return rows
+}
+
+// grepArgs reads the grep arguments of the kubectl tool; it returns nil if
+// grep is not set.
+func grepArgs(args map[string]any) (*LineGrep, error) {
+	pattern, err := stringArg(args, "grep")
+	if err != nil {
+		return nil, err
+	}
+	invert, err := boolArg(args, "grep_invert")
+	if err != nil {
+		return nil, err
+	}
+	context, hasContext, err := intArg(args, "context_lines")
+	if err != nil {
+		return nil, err
+	}
+	maxMatches, hasMax, err := intArg(args, "max_matches")
+	if err != nil {
+		return nil, err
+	}
+
+	if pattern == "" {
+		if invert || hasContext || hasMax {
+			return nil, fmt.Errorf("grep_invert, context_lines and max_matches need a grep pattern")
+		}
+		return nil, nil
+	}
+	if context < 0 || context > maxGrepContextLines {
+		return nil, fmt.Errorf("context_lines must be between 0 and %d", maxGrepContextLines)
+	}
+	if maxMatches < 0 {
+		return nil, fmt.Errorf("max_matches must be positive")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grep pattern: %v", err)
+	}
+	return &LineGrep{Pattern: re, Invert: invert, Context: context, MaxMatches: maxMatches}, nil
+}
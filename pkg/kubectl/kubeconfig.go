@@ -0,0 +1,88 @@
+package kubectl
+
+import (
+	"os"
+	"path/filepath"
+
+	"kubectl-go-mcp-server/internal/config"
+	"sigs.k8s.io/yaml"
+)
+
+// kubeconfigFile holds the parts of a kubeconfig file that tell where
+// commands run.
+type kubeconfigFile struct {
+	CurrentContext string `json:"current-context"`
+	Contexts       []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Namespace string `json:"namespace"`
+		} `json:"context"`
+	} `json:"contexts"`
+}
+
+// commandScope returns the context and namespace a command runs in: those its
+// flags select, else the kubeconfig's current context and that context's
+// namespace, or "default". The namespace is "*" for all namespaces. Parts
+// that cannot be determined are empty.
+func commandScope(kubeconfig string, parsed parsedCommand) (kubeContext, namespace string) {
+	if path := parsed.flags["--kubeconfig"]; path != "" {
+		kubeconfig = path
+	}
+	kubeContext = parsed.flags["--context"]
+	namespace = parsed.namespace()
+	if kubeContext != "" && namespace != "" {
+		return kubeContext, namespace
+	}
+
+	files := readKubeconfig(kubeconfig)
+	if kubeContext == "" {
+		for _, file := range files {
+			if file.CurrentContext != "" {
+				kubeContext = file.CurrentContext
+				break
+			}
+		}
+	}
+	if namespace != "" || kubeContext == "" {
+		return kubeContext, namespace
+	}
+	for _, file := range files {
+		for _, entry := range file.Contexts {
+			if entry.Name == kubeContext {
+				if entry.Context.Namespace != "" {
+					return kubeContext, entry.Context.Namespace
+				}
+				return kubeContext, "default"
+			}
+		}
+	}
+	return kubeContext, "default"
+}
+
+// currentContext returns the kubeconfig's current context, or "" if it has
+// none.
+func currentContext(kubeconfig string) string {
+	kubeContext, _ := commandScope(kubeconfig, parsedCommand{})
+	return kubeContext
+}
+
+// readKubeconfig reads the files of a kubeconfig path list, as kubectl would
+// get it in KUBECONFIG, skipping those that cannot be read.
+func readKubeconfig(kubeconfig string) []kubeconfigFile {
+	path, err := config.ValidateKubeconfigPath(kubeconfig)
+	if err != nil {
+		return nil
+	}
+	var files []kubeconfigFile
+	for _, name := range filepath.SplitList(path) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		var file kubeconfigFile
+		if yaml.Unmarshal(data, &file) == nil {
+			files = append(files, file)
+		}
+	}
+	return files
+}
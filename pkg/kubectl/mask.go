@@ -0,0 +1,39 @@
+package kubectl
+
+import (
+	"regexp"
+	"strings"
+)
+
+const maskedValue = "***"
+
+// flagValue matches a flag's value: a word, possibly with quoted parts.
+const flagValue = `((?:'[^']*'|"[^"]*"|[^\s'"])+)`
+
+var (
+	secretFlagPattern  = regexp.MustCompile(`(--(?:token|password|docker-password)(?:=|\s+))` + flagValue)
+	literalFlagPattern = regexp.MustCompile(`(--from-literal(?:=|\s+))` + flagValue)
+)
+
+// MaskSecrets replaces the values of flags that carry credentials in command,
+// such as --token and --password, with "***". For --from-literal the key is
+// kept and only the value masked.
+func MaskSecrets(command string) string {
+	command = secretFlagPattern.ReplaceAllString(command, "${1}"+maskedValue)
+	return literalFlagPattern.ReplaceAllStringFunc(command, func(match string) string {
+		groups := literalFlagPattern.FindStringSubmatch(match)
+		flag, value := groups[1], groups[2]
+		key, _, ok := strings.Cut(value, "=")
+		if !ok {
+			return flag + maskedValue
+		}
+		masked := key + "=" + maskedValue
+		// Close a quote opened before the '=', as in 'key=value'.
+		for _, quote := range []string{"'", `"`} {
+			if strings.Count(key, quote)%2 == 1 {
+				masked += quote
+			}
+		}
+		return flag + masked
+	})
+}
@@ -0,0 +1,288 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+const (
+	maxDrainTimeoutSeconds = 3600
+	mirrorPodAnnotation    = "kubernetes.io/config.mirror"
+)
+
+type NodeMaintenanceTool struct {
+	// Results, if set, is cleared after a node changes, since that moves
+	// pods in any namespace.
+	Results *ResultCache
+}
+
+type EvictionCandidate struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Owner     string `json:"owner,omitempty"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type NodeMaintenanceResult struct {
+	Node     string              `json:"node"`
+	Action   string              `json:"action"`
+	Command  string              `json:"command,omitempty"`
+	Pods     []EvictionCandidate `json:"pods,omitempty"`
+	Evicted  int                 `json:"evicted"`
+	Blocking int                 `json:"blocking"`
+	Output   string              `json:"output,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+func (t *NodeMaintenanceTool) Name() string {
+	return "node_maintenance"
+}
+
+func (t *NodeMaintenanceTool) Description() string {
+	return `Take a node in and out of maintenance in explicit steps: "preview" lists the pods a drain would evict, "cordon" marks the node unschedulable, "drain" evicts its pods and "uncordon" makes it schedulable again.
+
+Drains use the eviction API so PodDisruptionBudgets are respected, skip DaemonSet pods, never force-delete unmanaged pods and require timeout_seconds. Run "preview" first; a drain is refused while pods would block it.`
+}
+
+func (t *NodeMaintenanceTool) FunctionDefinition() *types.FunctionDefinition {
+	return &types.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &types.Schema{
+			Type: types.TypeObject,
+			Properties: map[string]*types.Schema{
+				"node": {
+					Type:        types.TypeString,
+					Description: "Name of the node",
+				},
+				"action": {
+					Type:        types.TypeString,
+					Description: `One of "preview", "cordon", "drain" or "uncordon"`,
+					Enum:        []any{"preview", "cordon", "drain", "uncordon"},
+				},
+				"timeout_seconds": {
+					Type:        types.TypeInteger,
+					Description: fmt.Sprintf("Required for drain: give up after this many seconds (at most %d)", maxDrainTimeoutSeconds),
+					Minimum:     types.Ptr(1.0),
+					Maximum:     types.Ptr(float64(maxDrainTimeoutSeconds)),
+				},
+				"delete_emptydir_data": {
+					Type:        types.TypeBoolean,
+					Description: "Allow draining pods that use emptyDir volumes; their local data is lost",
+				},
+				"grace_period_seconds": {
+					Type:        types.TypeInteger,
+					Description: "Override the pods' termination grace period during drain",
+					Minimum:     types.Ptr(1.0),
+				},
+			},
+			Required: []string{"node", "action"},
+		},
+		Annotations: &types.ToolAnnotations{
+			Title:           "Node maintenance",
+			DestructiveHint: true,
+			OpenWorldHint:   true,
+		},
+		OutputSchema: types.SchemaFor(&NodeMaintenanceResult{}),
+	}
+}
+
+func (t *NodeMaintenanceTool) Run(ctx context.Context, args map[string]any) (any, error) {
+	kubeconfig, workDir, err := contextPaths(ctx)
+	if err != nil {
+		return &NodeMaintenanceResult{Error: err.Error()}, nil
+	}
+
+	node, err := stringArg(args, "node")
+	if err != nil {
+		return &NodeMaintenanceResult{Error: err.Error()}, nil
+	}
+	action, err := stringArg(args, "action")
+	if err != nil {
+		return &NodeMaintenanceResult{Error: err.Error()}, nil
+	}
+	result := &NodeMaintenanceResult{Node: node, Action: action}
+	if err := validateObjectName("node", node); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	switch action {
+	case "cordon", "uncordon":
+		return t.run(ctx, result, fmt.Sprintf("kubectl %s %s", action, node), workDir, kubeconfig)
+	case "preview", "drain":
+	default:
+		result.Error = fmt.Sprintf("action must be one of preview, cordon, drain or uncordon, got %q", action)
+		return result, nil
+	}
+
+	deleteEmptyDir, err := boolArg(args, "delete_emptydir_data")
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	var command string
+	if action == "drain" {
+		if command, err = drainCommand(node, args, deleteEmptyDir); err != nil {
+			result.Error = err.Error()
+			return result, nil
+		}
+	}
+
+	if err := t.preview(ctx, result, deleteEmptyDir, workDir, kubeconfig); err != nil {
+		return nil, err
+	}
+	if action == "preview" || result.Error != "" {
+		return result, nil
+	}
+	if result.Blocking > 0 {
+		result.Error = fmt.Sprintf("refusing to drain: %d pod(s) would block the drain, see pods with action \"block\"", result.Blocking)
+		return result, nil
+	}
+	return t.run(ctx, result, command, workDir, kubeconfig)
+}
+
+func (t *NodeMaintenanceTool) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+// RequiredPermissions is what cordon and uncordon need; drains also need to
+// create evictions, which is checked by the API server when they run.
+func (t *NodeMaintenanceTool) RequiredPermissions() []types.Permission {
+	return []types.Permission{{Verb: "patch", Resource: "nodes"}}
+}
+
+func (t *NodeMaintenanceTool) CheckModifiesResource(args map[string]any) string {
+	switch args["action"] {
+	case "preview":
+		return "no"
+	case "cordon", "drain", "uncordon":
+		return "yes"
+	default:
+		return "unknown"
+	}
+}
+
+func (t *NodeMaintenanceTool) run(ctx context.Context, result *NodeMaintenanceResult, command, workDir, kubeconfig string) (*NodeMaintenanceResult, error) {
+	result.Command = command
+	execResult, err := RunKubectlCommand(ctx, command, workDir, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	t.Results.Clear()
+	result.Output = execResult.Stdout
+	result.Error = execResult.Error
+	return result, nil
+}
+
+func (t *NodeMaintenanceTool) preview(ctx context.Context, result *NodeMaintenanceResult, deleteEmptyDir bool, workDir, kubeconfig string) error {
+	command := "kubectl get pods --all-namespaces --field-selector spec.nodeName=" + result.Node + " -o json"
+	execResult, err := RunKubectlCommand(ctx, command, workDir, kubeconfig)
+	if err != nil {
+		return err
+	}
+	if execResult.Error != "" {
+		result.Error = fmt.Sprintf("listing pods on %s failed: %s: %s", result.Node, execResult.Error, strings.TrimSpace(execResult.Stdout))
+		return nil
+	}
+
+	var pods podList
+	if err := json.Unmarshal([]byte(execResult.Stdout), &pods); err != nil {
+		result.Error = fmt.Sprintf("parsing pod list: %v", err)
+		return nil
+	}
+
+	result.Pods = classifyEvictions(pods.Items, deleteEmptyDir)
+	for _, p := range result.Pods {
+		switch p.Action {
+		case "evict":
+			result.Evicted++
+		case "block":
+			result.Blocking++
+		}
+	}
+	return nil
+}
+
+// classifyEvictions decides, for each pod on a node, what a drain with this
+// tool's flags (--ignore-daemonsets, no --force) would do with it.
+func classifyEvictions(pods []pod, deleteEmptyDir bool) []EvictionCandidate {
+	candidates := make([]EvictionCandidate, 0, len(pods))
+	for _, p := range pods {
+		c := EvictionCandidate{Namespace: p.Metadata.Namespace, Name: p.Metadata.Name, Action: "evict"}
+		controller := controllerOf(p.Metadata)
+		if controller != nil {
+			c.Owner = controller.Kind + "/" + controller.Name
+		}
+
+		switch {
+		case p.Metadata.Annotations[mirrorPodAnnotation] != "":
+			c.Action, c.Reason = "skip", "static (mirror) pod"
+		case controller != nil && controller.Kind == "DaemonSet":
+			c.Action, c.Reason = "skip", "managed by a DaemonSet"
+		case p.Status.Phase == "Succeeded" || p.Status.Phase == "Failed":
+			c.Reason = "already terminated"
+		case controller == nil:
+			c.Action, c.Reason = "block", "not managed by a controller; it would be lost (drain --force is not used)"
+		case usesEmptyDir(p) && !deleteEmptyDir:
+			c.Action, c.Reason = "block", "uses emptyDir volumes; set delete_emptydir_data to discard their data"
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+func drainCommand(node string, args map[string]any, deleteEmptyDir bool) (string, error) {
+	timeout, set, err := intArg(args, "timeout_seconds")
+	if err != nil {
+		return "", err
+	}
+	if !set {
+		return "", fmt.Errorf("timeout_seconds is required for drain")
+	}
+	if timeout <= 0 || timeout > maxDrainTimeoutSeconds {
+		return "", fmt.Errorf("timeout_seconds must be between 1 and %d", maxDrainTimeoutSeconds)
+	}
+
+	command := fmt.Sprintf("kubectl drain %s --ignore-daemonsets --timeout=%ds", node, timeout)
+	if deleteEmptyDir {
+		command += " --delete-emptydir-data"
+	}
+
+	grace, set, err := intArg(args, "grace_period_seconds")
+	if err != nil {
+		return "", err
+	}
+	if set {
+		if grace < 1 {
+			return "", fmt.Errorf("grace_period_seconds must be at least 1")
+		}
+		command += " --grace-period=" + strconv.Itoa(grace)
+	}
+	return command, nil
+}
+
+func controllerOf(meta objectMeta) *ownerReference {
+	for i, ref := range meta.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			return &meta.OwnerReferences[i]
+		}
+	}
+	return nil
+}
+
+func usesEmptyDir(p pod) bool {
+	for _, v := range p.Spec.Volumes {
+		if len(v.EmptyDir) > 0 {
+			return true
+		}
+	}
+	return false
+}
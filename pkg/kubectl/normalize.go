@@ -0,0 +1,123 @@
+package kubectl
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"kubectl-go-mcp-server/internal/config"
+	"kubectl-go-mcp-server/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultStripFields are the fields Normalizer removes when none are
+// configured: bookkeeping that costs many tokens and rarely helps.
+var DefaultStripFields = []string{
+	"metadata.managedFields",
+	`metadata.annotations["kubectl.kubernetes.io/last-applied-configuration"]`,
+	"metadata.resourceVersion",
+	"metadata.uid",
+}
+
+// Normalizer removes noise fields from objects in "-o yaml" and "-o json"
+// output, and optionally rewrites the output as compact JSON.
+type Normalizer struct {
+	stripFields [][]string
+	compactJSON bool
+}
+
+// NewNormalizer returns a normalizer that removes stripFields from every
+// object, or DefaultStripFields if stripFields is nil. Fields are dotted
+// paths; keys containing dots or slashes are written as ["key"].
+func NewNormalizer(stripFields []string, compactJSON bool) (*Normalizer, error) {
+	if stripFields == nil {
+		stripFields = DefaultStripFields
+	}
+	n := &Normalizer{compactJSON: compactJSON}
+	for _, field := range stripFields {
+		path, err := config.ParseFieldPath(field)
+		if err != nil {
+			return nil, err
+		}
+		n.stripFields = append(n.stripFields, path)
+	}
+	return n, nil
+}
+
+// Normalize removes the configured fields from the object, or from each item
+// of a list, in output. Output that cannot be parsed is returned unchanged
+// with nil stats.
+func (n *Normalizer) Normalize(output []byte, isYAML bool) ([]byte, *types.NormalizeStats) {
+	data := output
+	if isYAML {
+		var err error
+		if data, err = yaml.YAMLToJSON(output); err != nil {
+			return output, nil
+		}
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var obj map[string]any
+	if err := decoder.Decode(&obj); err != nil {
+		return output, nil
+	}
+
+	stats := &types.NormalizeStats{BytesBefore: len(output)}
+	stats.StrippedFields = n.strip(obj)
+	if items, ok := obj["items"].([]any); ok {
+		for _, item := range items {
+			if object, ok := item.(map[string]any); ok {
+				stats.StrippedFields += n.strip(object)
+			}
+		}
+	}
+
+	normalized, err := n.encode(obj, isYAML)
+	if err != nil {
+		return output, nil
+	}
+	stats.BytesAfter = len(normalized)
+	// About four bytes per token for English text and YAML alike.
+	stats.EstimatedTokensSaved = max(stats.BytesBefore-stats.BytesAfter, 0) / 4
+	return normalized, stats
+}
+
+func (n *Normalizer) strip(obj map[string]any) int {
+	stripped := 0
+	for _, path := range n.stripFields {
+		parent := obj
+		for _, key := range path[:len(path)-1] {
+			parent, _ = parent[key].(map[string]any)
+		}
+		last := path[len(path)-1]
+		if _, ok := parent[last]; ok {
+			delete(parent, last)
+			stripped++
+			// Don't leave "annotations: {}" behind.
+			if len(parent) == 0 && len(path) > 1 {
+				grandparent := obj
+				for _, key := range path[:len(path)-2] {
+					grandparent, _ = grandparent[key].(map[string]any)
+				}
+				delete(grandparent, path[len(path)-2])
+			}
+		}
+	}
+	return stripped
+}
+
+func (n *Normalizer) encode(obj map[string]any, isYAML bool) ([]byte, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if !n.compactJSON && !isYAML {
+		// kubectl indents JSON by four spaces.
+		encoder.SetIndent("", "    ")
+	}
+	if err := encoder.Encode(obj); err != nil {
+		return nil, err
+	}
+	if isYAML && !n.compactJSON {
+		return yaml.JSONToYAML(out.Bytes())
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,132 @@
+package kubectl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	redactedValue         = "<redacted>"
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+var contextNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._:@/-]*[A-Za-z0-9])?$`)
+
+// ObjectRef identifies a single object, or all objects of a resource type
+// when Name is empty. An empty Context means the kubeconfig's current context;
+// an empty Namespace means cluster scope for a single object and all
+// namespaces for a list.
+type ObjectRef struct {
+	Context   string
+	Namespace string
+	Kind      string
+	Name      string
+}
+
+// GetCommand builds the read-only "kubectl get ... -o json" command for ref,
+// resolving Kind through discovery and checking it supports get.
+func (d *DiscoveryCache) GetCommand(ref ObjectRef) (string, APIResource, error) {
+	resource, ok := d.Resolve(ref.Kind)
+	if !ok {
+		return "", APIResource{}, fmt.Errorf("unknown resource type %q", ref.Kind)
+	}
+	if err := validateContextName(ref.Context); err != nil {
+		return "", APIResource{}, err
+	}
+	if err := validateNamespace(ref.Namespace); err != nil {
+		return "", APIResource{}, err
+	}
+
+	target := resource.Name
+	if group := resource.Group(); group != "" {
+		target += "." + group
+	}
+	if ref.Name != "" {
+		if err := validateObjectName(strings.ToLower(resource.Kind), ref.Name); err != nil {
+			return "", APIResource{}, err
+		}
+		target += " " + ref.Name
+	}
+
+	command := "kubectl get " + target
+	switch {
+	case !resource.Namespaced && ref.Namespace != "":
+		return "", APIResource{}, fmt.Errorf("%s is cluster-scoped and has no namespace", resource.Name)
+	case resource.Namespaced && ref.Namespace != "":
+		command += " -n " + ref.Namespace
+	case resource.Namespaced && ref.Name != "":
+		return "", APIResource{}, fmt.Errorf("%s is namespaced; a namespace is required", resource.Name)
+	case resource.Namespaced:
+		command += " --all-namespaces"
+	}
+	if ref.Context != "" {
+		command += " --context=" + ref.Context
+	}
+	command += " -o json"
+	if err := d.CheckCommand(command); err != nil {
+		return "", APIResource{}, err
+	}
+	return command, resource, nil
+}
+
+// WatchCommand builds the "kubectl get --watch-only" command that prints ref
+// as JSON each time it changes.
+func (d *DiscoveryCache) WatchCommand(ref ObjectRef) (string, error) {
+	command, resource, err := d.GetCommand(ref)
+	if err != nil {
+		return "", err
+	}
+	if len(resource.Verbs) > 0 && !resource.SupportsVerb("watch") {
+		return "", fmt.Errorf("resource %s does not support watch", resource.Name)
+	}
+	return strings.TrimSuffix(command, " -o json") + " --watch-only -o json", nil
+}
+
+// NamesCommand builds the "kubectl get ... -o name" command listing the
+// objects of ref's kind, for completion.
+func (d *DiscoveryCache) NamesCommand(ref ObjectRef) (string, error) {
+	ref.Name = ""
+	command, _, err := d.GetCommand(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(command, " -o json") + " -o name", nil
+}
+
+func validateContextName(name string) error {
+	if name != "" && (len(name) > 253 || !contextNamePattern.MatchString(name)) {
+		return fmt.Errorf("invalid context name %q", name)
+	}
+	return nil
+}
+
+// RedactSecrets replaces the values of Secret data, including the copy kept
+// in the last-applied-configuration annotation, in a decoded object or list.
+func RedactSecrets(obj map[string]any) {
+	if items, ok := obj["items"].([]any); ok {
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				RedactSecrets(m)
+			}
+		}
+	}
+	if obj["kind"] != "Secret" || obj["apiVersion"] != "v1" {
+		return
+	}
+
+	for _, field := range []string{"data", "stringData"} {
+		if data, ok := obj[field].(map[string]any); ok {
+			for key := range data {
+				data[key] = redactedValue
+			}
+		}
+	}
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		if annotations, ok := metadata["annotations"].(map[string]any); ok {
+			if _, ok := annotations[lastAppliedAnnotation]; ok {
+				annotations[lastAppliedAnnotation] = redactedValue
+			}
+		}
+	}
+}
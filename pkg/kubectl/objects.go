@@ -0,0 +1,73 @@
+package kubectl
+
+import "encoding/json"
+
+// Minimal views of the Kubernetes objects the structured tools read from
+// "kubectl get -o json". Only the fields the tools use are decoded.
+
+type objectMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	OwnerReferences []ownerReference  `json:"ownerReferences,omitempty"`
+}
+
+type ownerReference struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Controller *bool  `json:"controller,omitempty"`
+}
+
+type resourceRequirements struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+type container struct {
+	Name      string               `json:"name"`
+	Resources resourceRequirements `json:"resources"`
+}
+
+type volume struct {
+	Name     string          `json:"name"`
+	EmptyDir json.RawMessage `json:"emptyDir,omitempty"`
+}
+
+type pod struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		NodeName   string      `json:"nodeName,omitempty"`
+		Containers []container `json:"containers"`
+		Volumes    []volume    `json:"volumes,omitempty"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase,omitempty"`
+	} `json:"status"`
+}
+
+type podList struct {
+	Items []pod `json:"items"`
+}
+
+type scalableObject struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		Replicas *int `json:"replicas,omitempty"`
+	} `json:"spec"`
+}
+
+type horizontalPodAutoscaler struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		ScaleTargetRef struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"scaleTargetRef"`
+		MinReplicas *int `json:"minReplicas,omitempty"`
+		MaxReplicas int  `json:"maxReplicas"`
+	} `json:"spec"`
+}
+
+type horizontalPodAutoscalerList struct {
+	Items []horizontalPodAutoscaler `json:"items"`
+}
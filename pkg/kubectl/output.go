@@ -0,0 +1,236 @@
+package kubectl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+// maxProgressLineBytes caps the length of one progress message, so a single
+// huge line, such as minified JSON, is not buffered whole.
+const maxProgressLineBytes = 4096
+
+// truncationHint tells the model how to get a smaller result.
+const truncationHint = "Output was truncated. Narrow the query: select a namespace (-n) or labels (-l), use --field-selector, " +
+	"print fewer fields (-o name, -o custom-columns or -o jsonpath), or limit logs with --tail or --since."
+
+// limitedBuffer keeps the head and tail of what is written to it within the
+// byte and line limits, so memory stays bounded however much a command
+// prints. Zero limits are unlimited.
+type limitedBuffer struct {
+	headBytes, tailBytes int
+	headLines, tailLines int
+
+	head    []byte
+	headNL  int
+	tail    []byte
+	tailNL  int
+	total   int64
+	dropped int64
+}
+
+func newLimitedBuffer(limits types.OutputLimits) *limitedBuffer {
+	b := &limitedBuffer{headBytes: -1, tailBytes: -1, headLines: -1, tailLines: -1}
+	if limits.MaxBytes > 0 {
+		b.headBytes = limits.MaxBytes / 2
+		b.tailBytes = limits.MaxBytes - b.headBytes
+	}
+	if limits.MaxLines > 0 {
+		b.headLines = limits.MaxLines / 2
+		b.tailLines = limits.MaxLines - b.headLines
+	}
+	return b
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	b.total += int64(n)
+
+	if !b.headFull() {
+		take := len(p)
+		if b.headBytes >= 0 {
+			take = min(take, b.headBytes-len(b.head))
+		}
+		if b.headLines >= 0 {
+			take = min(take, afterNewlines(p, b.headLines-b.headNL))
+		}
+		b.head = append(b.head, p[:take]...)
+		b.headNL += bytes.Count(p[:take], []byte{'\n'})
+		p = p[take:]
+	}
+
+	b.tail = append(b.tail, p...)
+	b.tailNL += bytes.Count(p, []byte{'\n'})
+	if b.tailBytes >= 0 && len(b.tail) > b.tailBytes {
+		b.dropTail(len(b.tail) - b.tailBytes)
+	}
+	if b.tailLines >= 0 {
+		// Keep the last tailLines lines; a final line without newline counts.
+		lines := b.tailNL
+		if len(b.tail) > 0 && b.tail[len(b.tail)-1] != '\n' {
+			lines++
+		}
+		if extra := lines - b.tailLines; extra > 0 {
+			b.dropTail(afterNewlines(b.tail, extra))
+		}
+	}
+	return n, nil
+}
+
+// dropTail drops the first n bytes of the tail.
+func (b *limitedBuffer) dropTail(n int) {
+	b.dropped += int64(n)
+	b.tailNL -= bytes.Count(b.tail[:n], []byte{'\n'})
+	b.tail = append([]byte(nil), b.tail[n:]...)
+}
+
+func (b *limitedBuffer) headFull() bool {
+	return (b.headBytes >= 0 && len(b.head) >= b.headBytes) || (b.headLines >= 0 && b.headNL >= b.headLines)
+}
+
+// outputSink collects a command's combined output.
+type outputSink interface {
+	io.Writer
+	// finish sets the output fields of result.
+	finish(result *types.ExecResult)
+}
+
+func (b *limitedBuffer) finish(result *types.ExecResult) {
+	result.Stdout, result.Truncated = b.Output()
+	result.TotalBytes = b.total
+	if result.Truncated {
+		result.Hint = truncationHint
+	}
+}
+
+// Output returns the kept output, with a marker where bytes were dropped.
+func (b *limitedBuffer) Output() (output string, truncated bool) {
+	tail := b.tail
+	if b.dropped == 0 {
+		return string(b.head) + string(tail), false
+	}
+
+	var out strings.Builder
+	out.Write(b.head)
+	if len(b.head) > 0 && b.head[len(b.head)-1] != '\n' {
+		out.WriteByte('\n')
+	}
+	fmt.Fprintf(&out, "[... %d bytes truncated ...]\n", b.dropped)
+	out.Write(tail)
+	return out.String(), true
+}
+
+// processBytesFactor sets the stdout kept for processing, such as
+// filtering, when no limit is configured: this many times the output byte
+// limit, or unlimitedProcessBytes if that is unlimited.
+const (
+	processBytesFactor    = 10
+	unlimitedProcessBytes = 16 << 20
+)
+
+// cappedBuffer keeps the first limit bytes written to it. The buffer is not
+// embedded so that io.Copy cannot bypass Write through its ReadFrom.
+type cappedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.overflow = true
+		b.buf.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// writeProcessed writes stdout to sink, processed if processStdout is set.
+// If processing fails, the raw output is written and the error returned.
+func writeProcessed(sink io.Writer, stdout *cappedBuffer, process func([]byte) ([]byte, error), processStdout bool) error {
+	output := stdout.buf.Bytes()
+	var err error
+	switch {
+	case !processStdout:
+	case stdout.overflow:
+		err = fmt.Errorf("output exceeds %d bytes and was not processed; narrow the query or raise output.maxProcessBytes", stdout.limit)
+	default:
+		var processed []byte
+		if processed, err = process(output); err == nil {
+			output = processed
+		}
+	}
+	sink.Write(output)
+	return err
+}
+
+// afterNewlines returns the index just after the n-th newline in p, or len(p)
+// if p has fewer newlines.
+func afterNewlines(p []byte, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	offset := 0
+	for ; n > 0; n-- {
+		i := bytes.IndexByte(p[offset:], '\n')
+		if i < 0 {
+			return len(p)
+		}
+		offset += i + 1
+	}
+	return offset
+}
+
+// progressWriter reports each complete, non-empty line as it is written and
+// passes everything on to next.
+type progressWriter struct {
+	report   types.ProgressFunc
+	next     io.Writer
+	partial  []byte
+	skipping bool
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, _ := w.next.Write(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.appendPartial(p)
+			break
+		}
+		w.appendPartial(p[:i])
+		if !w.skipping {
+			w.send(w.partial)
+		}
+		w.partial, w.skipping = w.partial[:0], false
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+func (w *progressWriter) appendPartial(p []byte) {
+	if w.skipping {
+		return
+	}
+	w.partial = append(w.partial, p...)
+	if len(w.partial) > maxProgressLineBytes {
+		w.send(w.partial[:maxProgressLineBytes])
+		w.partial, w.skipping = w.partial[:0], true
+	}
+}
+
+func (w *progressWriter) flush() {
+	if !w.skipping {
+		w.send(w.partial)
+	}
+	w.partial = nil
+}
+
+func (w *progressWriter) send(line []byte) {
+	if text := strings.TrimSpace(string(line)); text != "" {
+		w.report(text)
+	}
+}
@@ -0,0 +1,300 @@
+package kubectl
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+// DefaultOutputPageTTL is how long stored output can be fetched when no TTL
+// is configured.
+const DefaultOutputPageTTL = 10 * time.Minute
+
+const pagesHint = "Output continues. Call fetch_output with next_cursor for the next page, or narrow the query."
+
+// OutputStore keeps command output that exceeds the output limits in files
+// under a private directory, so it can be fetched in pages. Entries expire
+// after TTL; Close removes everything.
+type OutputStore struct {
+	workDir string
+
+	mu      sync.Mutex
+	ttl     time.Duration
+	dir     string
+	entries map[string]*storedOutput
+}
+
+type storedOutput struct {
+	path    string
+	size    int64
+	limits  types.OutputLimits
+	expires time.Time
+}
+
+// NewOutputStore creates a store whose directory is created under workDir
+// the first time output is stored.
+func NewOutputStore(workDir string, ttl time.Duration) *OutputStore {
+	if ttl <= 0 {
+		ttl = DefaultOutputPageTTL
+	}
+	return &OutputStore{workDir: workDir, ttl: ttl, entries: make(map[string]*storedOutput)}
+}
+
+// SetTTL changes the TTL of output stored from now on; zero or less means
+// DefaultOutputPageTTL.
+func (s *OutputStore) SetTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultOutputPageTTL
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+}
+
+// Entries returns the number of stored outputs that have not expired.
+func (s *OutputStore) Entries() int {
+	s.RemoveExpired()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Run removes expired output every interval until ctx is cancelled.
+func (s *OutputStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RemoveExpired()
+		}
+	}
+}
+
+func (s *OutputStore) RemoveExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, entry := range s.entries {
+		if now.After(entry.expires) {
+			os.Remove(entry.path)
+			delete(s.entries, id)
+		}
+	}
+}
+
+// Close removes all stored output.
+func (s *OutputStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]*storedOutput)
+	if s.dir == "" {
+		return nil
+	}
+	dir := s.dir
+	s.dir = ""
+	return os.RemoveAll(dir)
+}
+
+func (s *OutputStore) create() (*os.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dir == "" {
+		if s.workDir != "" {
+			if err := os.MkdirAll(s.workDir, 0o755); err != nil {
+				return nil, err
+			}
+		}
+		dir, err := os.MkdirTemp(s.workDir, "output-")
+		if err != nil {
+			return nil, err
+		}
+		s.dir = dir
+	}
+	return os.CreateTemp(s.dir, "page-")
+}
+
+func (s *OutputStore) add(path string, size int64, limits types.OutputLimits) (string, error) {
+	var random [16]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(random[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[id] = &storedOutput{path: path, size: size, limits: limits, expires: time.Now().Add(s.ttl)}
+	return id, nil
+}
+
+// Page returns the page of stored output that cursor points at and the
+// cursor of the following page, which is empty after the last page.
+func (s *OutputStore) Page(cursor string) (page string, next string, offset, size int64, err error) {
+	s.RemoveExpired()
+
+	id, offsetText, ok := strings.Cut(cursor, ".")
+	offset, parseErr := strconv.ParseInt(offsetText, 10, 64)
+	s.mu.Lock()
+	entry := s.entries[id]
+	s.mu.Unlock()
+	if !ok || parseErr != nil || entry == nil || offset < 0 || offset > entry.size {
+		return "", "", 0, 0, fmt.Errorf("cursor is invalid or has expired; run the command again")
+	}
+
+	data, err := readPage(entry.path, offset, entry.limits)
+	if err != nil {
+		return "", "", 0, 0, fmt.Errorf("reading stored output: %w", err)
+	}
+	if end := offset + int64(len(data)); end < entry.size {
+		next = pageCursor(id, end)
+	}
+	return string(data), next, offset, entry.size, nil
+}
+
+func pageCursor(id string, offset int64) string {
+	return id + "." + strconv.FormatInt(offset, 10)
+}
+
+// readPage reads at most limits.MaxBytes bytes and limits.MaxLines lines from
+// offset, ending the page at a line break where possible.
+func readPage(path string, offset int64, limits types.OutputLimits) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	maxBytes := limits.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = 1 << 20
+	}
+	data := make([]byte, maxBytes)
+	n, err := f.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	data = data[:n]
+
+	if limits.MaxLines > 0 {
+		data = data[:afterNewlines(data, limits.MaxLines)]
+	}
+	if len(data) == maxBytes {
+		if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+			data = data[:i+1]
+		}
+	}
+	return data, nil
+}
+
+// spoolWriter holds output in memory while it is within the limits and moves
+// it to a file in the store once it exceeds them. If the file cannot be
+// written the output is truncated instead.
+type spoolWriter struct {
+	store  *OutputStore
+	limits types.OutputLimits
+
+	memory   bytes.Buffer
+	lines    int
+	file     *os.File
+	failed   bool
+	fallback *limitedBuffer
+}
+
+func newSpoolWriter(store *OutputStore, limits types.OutputLimits) *spoolWriter {
+	return &spoolWriter{store: store, limits: limits, fallback: newLimitedBuffer(limits)}
+}
+
+func (w *spoolWriter) Write(p []byte) (int, error) {
+	w.fallback.Write(p)
+	if w.failed {
+		return len(p), nil
+	}
+
+	if w.file == nil {
+		w.memory.Write(p)
+		w.lines += bytes.Count(p, []byte{'\n'})
+		if !w.exceeded() {
+			return len(p), nil
+		}
+		file, err := w.store.create()
+		if err == nil {
+			_, err = file.Write(w.memory.Bytes())
+		}
+		w.memory.Reset()
+		if err != nil {
+			w.fail(file, err)
+			return len(p), nil
+		}
+		w.file = file
+		return len(p), nil
+	}
+
+	if _, err := w.file.Write(p); err != nil {
+		w.fail(w.file, err)
+	}
+	return len(p), nil
+}
+
+func (w *spoolWriter) exceeded() bool {
+	return (w.limits.MaxBytes > 0 && w.memory.Len() > w.limits.MaxBytes) ||
+		(w.limits.MaxLines > 0 && w.lines > w.limits.MaxLines)
+}
+
+func (w *spoolWriter) fail(file *os.File, err error) {
+	log.Printf("Storing command output failed, truncating it instead: %v", err)
+	if file != nil {
+		file.Close()
+		os.Remove(file.Name())
+	}
+	w.file = nil
+	w.failed = true
+}
+
+// finish sets the output fields of result: all output if it fits, the first
+// page and a cursor for the rest if it was stored, or the truncated output.
+func (w *spoolWriter) finish(result *types.ExecResult) {
+	if w.failed {
+		w.fallback.finish(result)
+		return
+	}
+	result.TotalBytes = w.fallback.total
+	if w.file == nil {
+		result.Stdout = w.memory.String()
+		return
+	}
+
+	path := w.file.Name()
+	if err := w.file.Close(); err != nil {
+		w.fail(nil, err)
+		os.Remove(path)
+		w.fallback.finish(result)
+		return
+	}
+	id, err := w.store.add(path, w.fallback.total, w.limits)
+	if err == nil {
+		result.Stdout, result.NextCursor, _, _, err = w.store.Page(pageCursor(id, 0))
+	}
+	if err != nil {
+		w.fail(nil, err)
+		os.Remove(path)
+		w.fallback.finish(result)
+		return
+	}
+	result.Hint = pagesHint
+}
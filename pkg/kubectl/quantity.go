@@ -0,0 +1,75 @@
+package kubectl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var binarySuffixes = map[string]float64{
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+	"Ei": 1 << 60,
+}
+
+var decimalSuffixes = map[string]float64{
+	"n": 1e-9,
+	"u": 1e-6,
+	"m": 1e-3,
+	"k": 1e3,
+	"K": 1e3,
+	"M": 1e6,
+	"G": 1e9,
+	"T": 1e12,
+	"P": 1e15,
+	"E": 1e18,
+}
+
+// ParseQuantity converts a Kubernetes resource quantity such as "250m",
+// "1.5Gi" or "2e3" to its value in base units.
+func ParseQuantity(quantity string) (float64, error) {
+	q := strings.TrimSpace(quantity)
+	if q == "" {
+		return 0, fmt.Errorf("empty quantity")
+	}
+
+	number, multiplier := q, 1.0
+	if len(q) > 2 {
+		if m, ok := binarySuffixes[q[len(q)-2:]]; ok {
+			number, multiplier = q[:len(q)-2], m
+		}
+	}
+	if multiplier == 1 {
+		if m, ok := decimalSuffixes[q[len(q)-1:]]; ok {
+			number, multiplier = q[:len(q)-1], m
+		}
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("invalid quantity %q", quantity)
+	}
+	return value * multiplier, nil
+}
+
+// ParseCPUMillicores converts a CPU quantity ("250m", "0.5", "2") to millicores.
+func ParseCPUMillicores(quantity string) (int64, error) {
+	value, err := ParseQuantity(quantity)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(value * 1000)), nil
+}
+
+// ParseMemoryBytes converts a memory quantity ("128Mi", "1G") to bytes.
+func ParseMemoryBytes(quantity string) (int64, error) {
+	value, err := ParseQuantity(quantity)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(value)), nil
+}
@@ -0,0 +1,456 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+const DefaultUsageThresholdPercent = 80
+
+type ResourceUsageTool struct {
+	// ThresholdPercent is used when a call does not set threshold_percent.
+	ThresholdPercent int
+}
+
+func (t *ResourceUsageTool) defaultThreshold() int {
+	if t.ThresholdPercent > 0 {
+		return t.ThresholdPercent
+	}
+	return DefaultUsageThresholdPercent
+}
+
+type ResourceUsage struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace,omitempty"`
+	CPUMillicores int64  `json:"cpu_millicores"`
+	MemoryBytes   int64  `json:"memory_bytes"`
+
+	// Node usage relative to allocatable capacity, as reported by kubectl top.
+	CPUPercent    *float64 `json:"cpu_percent,omitempty"`
+	MemoryPercent *float64 `json:"memory_percent,omitempty"`
+
+	// Pod usage relative to the sum of its containers' requests and limits.
+	CPURequestMillicores int64    `json:"cpu_request_millicores,omitempty"`
+	CPULimitMillicores   int64    `json:"cpu_limit_millicores,omitempty"`
+	MemoryRequestBytes   int64    `json:"memory_request_bytes,omitempty"`
+	MemoryLimitBytes     int64    `json:"memory_limit_bytes,omitempty"`
+	CPURequestPercent    *float64 `json:"cpu_request_percent,omitempty"`
+	CPULimitPercent      *float64 `json:"cpu_limit_percent,omitempty"`
+	MemoryRequestPercent *float64 `json:"memory_request_percent,omitempty"`
+	MemoryLimitPercent   *float64 `json:"memory_limit_percent,omitempty"`
+
+	OverThreshold bool `json:"over_threshold,omitempty"`
+}
+
+type ResourceUsageResult struct {
+	Target           string          `json:"target"`
+	SortBy           string          `json:"sort_by"`
+	ThresholdPercent int             `json:"threshold_percent"`
+	Items            []ResourceUsage `json:"items"`
+	Total            int             `json:"total"`
+	OverThreshold    int             `json:"over_threshold"`
+	Error            string          `json:"error,omitempty"`
+}
+
+func (t *ResourceUsageTool) Name() string {
+	return "resource_usage"
+}
+
+func (t *ResourceUsageTool) Description() string {
+	return `Report CPU (millicores) and memory (bytes) usage of nodes or pods from the metrics API (kubectl top), sorted and optionally limited to the top N.
+
+For pods, include_utilization joins usage with the containers' requests and limits and reports percentages. Nodes and pods above threshold_percent are flagged with over_threshold (pods are compared against their limits, or requests when no limit is set).`
+}
+
+func (t *ResourceUsageTool) FunctionDefinition() *types.FunctionDefinition {
+	return &types.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &types.Schema{
+			Type: types.TypeObject,
+			Properties: map[string]*types.Schema{
+				"target": {
+					Type:        types.TypeString,
+					Description: `What to report on: "nodes" or "pods"`,
+					Enum:        []any{"nodes", "pods"},
+				},
+				"namespace": {
+					Type:        types.TypeString,
+					Description: "Namespace of the pods (defaults to the current namespace)",
+				},
+				"all_namespaces": {
+					Type:        types.TypeBoolean,
+					Description: "Report pods in all namespaces",
+				},
+				"selector": {
+					Type:        types.TypeString,
+					Description: "Equality-based label selector, e.g. app=web",
+				},
+				"sort_by": {
+					Type:        types.TypeString,
+					Description: `Sort descending by "cpu" (default) or "memory", or ascending by "name"`,
+					Enum:        []any{"cpu", "memory", "name"},
+					Default:     "cpu",
+				},
+				"top": {
+					Type:        types.TypeInteger,
+					Description: "Only return the first N entries after sorting",
+					Minimum:     types.Ptr(0.0),
+				},
+				"include_utilization": {
+					Type:        types.TypeBoolean,
+					Description: "For pods, join with requests/limits from the pod specs and report utilization percentages",
+				},
+				"threshold_percent": {
+					Type:        types.TypeInteger,
+					Description: fmt.Sprintf("Flag entries whose utilization exceeds this percentage (default %d)", t.defaultThreshold()),
+					Minimum:     types.Ptr(1.0),
+				},
+			},
+			Required: []string{"target"},
+		},
+		Annotations: &types.ToolAnnotations{
+			Title:         "Show resource usage",
+			ReadOnlyHint:  true,
+			OpenWorldHint: true,
+		},
+		OutputSchema: types.SchemaFor(&ResourceUsageResult{}),
+	}
+}
+
+func (t *ResourceUsageTool) Run(ctx context.Context, args map[string]any) (any, error) {
+	kubeconfig, workDir, err := contextPaths(ctx)
+	if err != nil {
+		return &ResourceUsageResult{Error: err.Error()}, nil
+	}
+
+	opts, err := t.parseArgs(args)
+	if err != nil {
+		return &ResourceUsageResult{Error: err.Error()}, nil
+	}
+	result := &ResourceUsageResult{Target: opts.target, SortBy: opts.sortBy, ThresholdPercent: opts.threshold}
+
+	scope := opts.scopeFlags()
+	execResult, err := RunKubectlCommand(ctx, "kubectl top "+opts.target+scope, workDir, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if execResult.Error != "" {
+		result.Error = fmt.Sprintf("kubectl top failed: %s: %s", execResult.Error, strings.TrimSpace(execResult.Stdout))
+		return result, nil
+	}
+
+	items, err := ParseTopOutput(execResult.Stdout)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	if opts.target == "pods" && opts.utilization {
+		podsResult, err := RunKubectlCommand(ctx, "kubectl get pods"+scope+" -o json", workDir, kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		if podsResult.Error != "" {
+			result.Error = fmt.Sprintf("reading pod resources failed: %s: %s", podsResult.Error, strings.TrimSpace(podsResult.Stdout))
+			return result, nil
+		}
+		var pods podList
+		if err := json.Unmarshal([]byte(podsResult.Stdout), &pods); err != nil {
+			result.Error = fmt.Sprintf("parsing pod list: %v", err)
+			return result, nil
+		}
+		joinPodResources(items, pods.Items)
+	}
+
+	for i := range items {
+		items[i].OverThreshold = overThreshold(items[i], float64(opts.threshold))
+		if items[i].OverThreshold {
+			result.OverThreshold++
+		}
+	}
+
+	SortResourceUsage(items, opts.sortBy)
+	result.Total = len(items)
+	if opts.top > 0 && opts.top < len(items) {
+		items = items[:opts.top]
+	}
+	result.Items = items
+	return result, nil
+}
+
+func (t *ResourceUsageTool) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+func (t *ResourceUsageTool) CheckModifiesResource(args map[string]any) string {
+	return "no"
+}
+
+type usageOptions struct {
+	target        string
+	namespace     string
+	allNamespaces bool
+	selector      string
+	sortBy        string
+	top           int
+	utilization   bool
+	threshold     int
+}
+
+func (t *ResourceUsageTool) parseArgs(args map[string]any) (*usageOptions, error) {
+	opts := &usageOptions{}
+	var err error
+
+	if opts.target, err = stringArg(args, "target"); err != nil {
+		return nil, err
+	}
+	if opts.target != "nodes" && opts.target != "pods" {
+		return nil, fmt.Errorf("target must be \"nodes\" or \"pods\", got %q", opts.target)
+	}
+	if opts.namespace, err = stringArg(args, "namespace"); err != nil {
+		return nil, err
+	}
+	if err := validateNamespace(opts.namespace); err != nil {
+		return nil, err
+	}
+	if opts.allNamespaces, err = boolArg(args, "all_namespaces"); err != nil {
+		return nil, err
+	}
+	if opts.selector, err = stringArg(args, "selector"); err != nil {
+		return nil, err
+	}
+	if err := validateSelector(opts.selector); err != nil {
+		return nil, err
+	}
+	if opts.target == "nodes" && (opts.namespace != "" || opts.allNamespaces) {
+		return nil, fmt.Errorf("namespace and all_namespaces only apply to pods")
+	}
+
+	if opts.sortBy, err = stringArg(args, "sort_by"); err != nil {
+		return nil, err
+	}
+	if opts.sortBy == "" {
+		opts.sortBy = "cpu"
+	}
+	if opts.sortBy != "cpu" && opts.sortBy != "memory" && opts.sortBy != "name" {
+		return nil, fmt.Errorf("sort_by must be \"cpu\", \"memory\" or \"name\", got %q", opts.sortBy)
+	}
+
+	if opts.top, _, err = intArg(args, "top"); err != nil {
+		return nil, err
+	}
+	if opts.top < 0 {
+		return nil, fmt.Errorf("top must not be negative")
+	}
+	if opts.utilization, err = boolArg(args, "include_utilization"); err != nil {
+		return nil, err
+	}
+
+	threshold, set, err := intArg(args, "threshold_percent")
+	if err != nil {
+		return nil, err
+	}
+	opts.threshold = t.defaultThreshold()
+	if set {
+		opts.threshold = threshold
+	}
+	if opts.threshold <= 0 {
+		return nil, fmt.Errorf("threshold_percent must be positive")
+	}
+	return opts, nil
+}
+
+func (o *usageOptions) scopeFlags() string {
+	var flags string
+	if o.allNamespaces {
+		flags += " --all-namespaces"
+	} else if o.namespace != "" {
+		flags += " -n " + o.namespace
+	}
+	if o.selector != "" {
+		flags += " -l " + o.selector
+	}
+	return flags
+}
+
+// ParseTopOutput parses the tables printed by "kubectl top nodes" and
+// "kubectl top pods" (with or without a NAMESPACE column). Nodes without
+// metrics ("<unknown>") are skipped.
+func ParseTopOutput(output string) ([]ResourceUsage, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) == 0 || !strings.Contains(lines[0], "NAME") {
+		return nil, fmt.Errorf("unexpected kubectl top output: %q", strings.TrimSpace(output))
+	}
+
+	columns := make(map[string]int)
+	for i, name := range strings.Fields(lines[0]) {
+		name = strings.NewReplacer("(", "", ")", "").Replace(name)
+		columns[name] = i
+	}
+	cpuCol, hasCPU := columns["CPUcores"]
+	memCol, hasMem := columns["MEMORYbytes"]
+	if !hasCPU || !hasMem {
+		return nil, fmt.Errorf("unexpected kubectl top header: %q", lines[0])
+	}
+
+	var items []ResourceUsage
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) != len(columns) {
+			continue
+		}
+		if fields[cpuCol] == "<unknown>" {
+			continue
+		}
+
+		item := ResourceUsage{Name: fields[columns["NAME"]]}
+		if i, ok := columns["NAMESPACE"]; ok {
+			item.Namespace = fields[i]
+		}
+
+		var err error
+		if item.CPUMillicores, err = ParseCPUMillicores(fields[cpuCol]); err != nil {
+			return nil, fmt.Errorf("parsing CPU of %s: %w", item.Name, err)
+		}
+		if item.MemoryBytes, err = ParseMemoryBytes(fields[memCol]); err != nil {
+			return nil, fmt.Errorf("parsing memory of %s: %w", item.Name, err)
+		}
+		if i, ok := columns["CPU%"]; ok {
+			item.CPUPercent = parsePercent(fields[i])
+		}
+		if i, ok := columns["MEMORY%"]; ok {
+			item.MemoryPercent = parsePercent(fields[i])
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// joinPodResources fills in requests, limits and utilization percentages of
+// pod usage entries from the matching pod specs. A limit is only reported when
+// every container sets it, since the pod is otherwise unbounded.
+func joinPodResources(items []ResourceUsage, pods []pod) {
+	byName := make(map[string]*pod, len(pods))
+	for i := range pods {
+		byName[pods[i].Metadata.Namespace+"/"+pods[i].Metadata.Name] = &pods[i]
+	}
+
+	for i := range items {
+		item := &items[i]
+		p, ok := byName[item.Namespace+"/"+item.Name]
+		if !ok && item.Namespace == "" {
+			// Single-namespace top output has no NAMESPACE column.
+			for j := range pods {
+				if pods[j].Metadata.Name == item.Name {
+					p, ok = &pods[j], true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		var cpuLimit, memLimit int64
+		cpuLimited, memLimited := true, true
+		for _, c := range p.Spec.Containers {
+			item.CPURequestMillicores += quantityOrZero(c.Resources.Requests["cpu"], ParseCPUMillicores)
+			item.MemoryRequestBytes += quantityOrZero(c.Resources.Requests["memory"], ParseMemoryBytes)
+			if v, ok := c.Resources.Limits["cpu"]; ok {
+				cpuLimit += quantityOrZero(v, ParseCPUMillicores)
+			} else {
+				cpuLimited = false
+			}
+			if v, ok := c.Resources.Limits["memory"]; ok {
+				memLimit += quantityOrZero(v, ParseMemoryBytes)
+			} else {
+				memLimited = false
+			}
+		}
+		if cpuLimited {
+			item.CPULimitMillicores = cpuLimit
+		}
+		if memLimited {
+			item.MemoryLimitBytes = memLimit
+		}
+
+		item.CPURequestPercent = percentOf(item.CPUMillicores, item.CPURequestMillicores)
+		item.CPULimitPercent = percentOf(item.CPUMillicores, item.CPULimitMillicores)
+		item.MemoryRequestPercent = percentOf(item.MemoryBytes, item.MemoryRequestBytes)
+		item.MemoryLimitPercent = percentOf(item.MemoryBytes, item.MemoryLimitBytes)
+	}
+}
+
+func SortResourceUsage(items []ResourceUsage, sortBy string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		switch sortBy {
+		case "memory":
+			return items[i].MemoryBytes > items[j].MemoryBytes
+		case "name":
+			if items[i].Namespace != items[j].Namespace {
+				return items[i].Namespace < items[j].Namespace
+			}
+			return items[i].Name < items[j].Name
+		default:
+			return items[i].CPUMillicores > items[j].CPUMillicores
+		}
+	})
+}
+
+func overThreshold(item ResourceUsage, threshold float64) bool {
+	for _, percent := range []*float64{
+		item.CPUPercent,
+		item.MemoryPercent,
+		firstNonNil(item.CPULimitPercent, item.CPURequestPercent),
+		firstNonNil(item.MemoryLimitPercent, item.MemoryRequestPercent),
+	} {
+		if percent != nil && *percent > threshold {
+			return true
+		}
+	}
+	return false
+}
+
+func firstNonNil(values ...*float64) *float64 {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func percentOf(used, total int64) *float64 {
+	if total <= 0 {
+		return nil
+	}
+	percent := math.Round(float64(used)/float64(total)*1000) / 10
+	return &percent
+}
+
+func parsePercent(value string) *float64 {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return nil
+	}
+	return &percent
+}
+
+func quantityOrZero(quantity string, parse func(string) (int64, error)) int64 {
+	if quantity == "" {
+		return 0
+	}
+	value, err := parse(quantity)
+	if err != nil {
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,262 @@
+package kubectl
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+const maxResultCacheEntries = 256
+
+// cacheableVerbs are the read-only subcommands whose results are cached.
+// exec, port-forward and proxy are classified as not modifying resources but
+// don't return repeatable results.
+var cacheableVerbs = map[string]bool{
+	"get": true, "describe": true, "logs": true, "top": true,
+	"version": true, "cluster-info": true,
+}
+
+// flagAliases maps short flags to their long form.
+var flagAliases = map[string]string{
+	"-n": "--namespace", "-A": "--all-namespaces", "-o": "--output",
+	"-l": "--selector", "-c": "--container", "-f": "--filename",
+	"-L": "--label-columns", "-p": "--patch", "-w": "--watch",
+}
+
+// ResultCache keeps the results of read-only kubectl commands for a short
+// time, so repeated queries during one investigation don't run kubectl each
+// time. Results are dropped when a command modifies their namespace. The
+// methods do nothing on a nil cache, and a cache with no TTL keeps nothing.
+type ResultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*cachedResult
+}
+
+type cachedResult struct {
+	result    types.ExecResult
+	namespace string
+	expires   time.Time
+}
+
+func NewResultCache(ttl time.Duration) *ResultCache {
+	return &ResultCache{ttl: ttl, entries: make(map[string]*cachedResult)}
+}
+
+// SetTTL changes how long results are kept from now on; a TTL of zero or
+// less turns the cache off and drops what it holds.
+func (c *ResultCache) SetTTL(ttl time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+	if ttl <= 0 {
+		c.entries = make(map[string]*cachedResult)
+	}
+}
+
+// Get returns a copy of the cached result for key, marked as cached.
+func (c *ResultCache) Get(key string) (*types.ExecResult, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	result := entry.result
+	result.Cached = true
+	return &result, true
+}
+
+// Put caches result under key; namespace is the namespace the command read,
+// "" for the kubeconfig's default or cluster-scoped resources, or "*" for
+// all namespaces.
+func (c *ResultCache) Put(key, namespace string, result *types.ExecResult) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	if len(c.entries) >= maxResultCacheEntries {
+		oldest := ""
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			} else if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		if len(c.entries) >= maxResultCacheEntries {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = &cachedResult{result: *result, namespace: namespace, expires: now.Add(c.ttl)}
+}
+
+// Invalidate drops the results a change in namespace may have affected: those
+// of namespace, of the default namespace, of cluster-scoped resources and of
+// all namespaces. A change in namespace "", which may be any namespace, or in
+// "*" drops everything.
+func (c *ResultCache) Invalidate(namespace string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if namespace == "" || namespace == "*" || entry.namespace == namespace || entry.namespace == "" || entry.namespace == "*" {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Clear drops all results, e.g. when the current context changes.
+func (c *ResultCache) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*cachedResult)
+}
+
+// Len returns the number of cached results, including expired ones not yet
+// dropped.
+func (c *ResultCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// parsedCommand is a kubectl command with its flags in canonical form.
+type parsedCommand struct {
+	verb       string
+	positional []string
+	flags      map[string]string
+	// rest holds the words after "--", passed on verbatim.
+	rest []string
+}
+
+func parseCommand(command string) parsedCommand {
+	parsed := parsedCommand{flags: make(map[string]string)}
+	words := strings.Fields(command)
+	if len(words) > 0 {
+		words = words[1:]
+	}
+	if len(words) > 0 {
+		parsed.verb = words[0]
+		words = words[1:]
+	}
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case word == "--":
+			parsed.rest = words[i+1:]
+			return parsed
+		case !strings.HasPrefix(word, "-") || word == "-":
+			parsed.positional = append(parsed.positional, word)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(word, "=")
+		if !hasValue && !strings.HasPrefix(word, "--") && len(word) > 2 && flagsWithValue[word[:2]] {
+			// -nshop
+			name, value, hasValue = word[:2], word[2:], true
+		}
+		// For logs, -f is --follow rather than --filename.
+		if parsed.verb == "logs" && name == "-f" {
+			name = "--follow"
+		}
+		if long, ok := flagAliases[name]; ok {
+			name = long
+		}
+		if !hasValue && flagsWithValue[name] && i+1 < len(words) {
+			value, hasValue = words[i+1], true
+			i++
+		}
+		if !hasValue {
+			value = "true"
+		}
+		parsed.flags[name] = value
+	}
+	return parsed
+}
+
+// normalized renders the command with its flags sorted, so that equivalent
+// spellings of a command produce the same string.
+func (p parsedCommand) normalized() string {
+	words := append([]string{"kubectl", p.verb}, p.positional...)
+	names := make([]string, 0, len(p.flags))
+	for name := range p.flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		words = append(words, name+"="+p.flags[name])
+	}
+	if p.rest != nil {
+		words = append(append(words, "--"), p.rest...)
+	}
+	return strings.Join(words, " ")
+}
+
+// namespace returns the namespace the command acts in: "*" for all
+// namespaces and "" when it is not set.
+func (p parsedCommand) namespace() string {
+	if all := p.flags["--all-namespaces"]; all != "" && all != "false" {
+		return "*"
+	}
+	return p.flags["--namespace"]
+}
+
+func (p parsedCommand) cacheable() bool {
+	if !cacheableVerbs[p.verb] {
+		return false
+	}
+	for _, flag := range []string{"--watch", "--watch-only", "--follow"} {
+		if value, ok := p.flags[flag]; ok && value != "false" {
+			return false
+		}
+	}
+	return true
+}
+
+// resultCacheKey identifies a command's result: the kubeconfig and context it
+// ran against, the normalized command and the output processing arguments.
+// The context is the one in use, so switching contexts doesn't serve results
+// from the previous cluster.
+func resultCacheKey(kubeconfig string, parsed parsedCommand, args map[string]any) string {
+	kubeContext, _ := commandScope(kubeconfig, parsed)
+	key := []string{filepath.Clean(kubeconfig), kubeContext, parsed.normalized()}
+	for _, name := range []string{"filter", "grep", "grep_invert", "context_lines", "max_matches", "parse_table"} {
+		if value, ok := args[name]; ok && value != nil {
+			key = append(key, name+"="+fmt.Sprint(value))
+		}
+	}
+	return strings.Join(key, "\x00")
+}
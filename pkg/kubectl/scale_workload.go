@@ -0,0 +1,287 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+// ScaleLimits bounds the replica counts scale_workload may set. A MaxReplicas
+// or MaxDeltaPercent of zero disables that check.
+type ScaleLimits struct {
+	MinReplicas     int
+	MaxReplicas     int
+	MaxDeltaPercent int
+}
+
+// scalableResources are the built-in workload resources that expose the scale
+// subresource, keyed by resource name and API group.
+var scalableResources = map[string]string{
+	"deployments":            "apps",
+	"statefulsets":           "apps",
+	"replicasets":            "apps",
+	"replicationcontrollers": "",
+}
+
+type ScaleWorkloadTool struct {
+	Discovery *DiscoveryCache
+	Limits    ScaleLimits
+	// Results, if set, is invalidated for the namespace after scaling.
+	Results *ResultCache
+}
+
+type ScaleWorkloadResult struct {
+	Kind      string   `json:"kind"`
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace,omitempty"`
+	Command   string   `json:"command,omitempty"`
+	Before    *int     `json:"before,omitempty"`
+	Requested int      `json:"requested"`
+	After     *int     `json:"after,omitempty"`
+	HPA       string   `json:"hpa,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+	Output    string   `json:"output,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func (t *ScaleWorkloadTool) Name() string {
+	return "scale_workload"
+}
+
+func (t *ScaleWorkloadTool) Description() string {
+	return `Scale a Deployment, StatefulSet, ReplicaSet or ReplicationController to a new replica count.
+
+The tool reads the current replica count first and refuses changes outside the server's configured bounds (minimum, maximum and largest relative change). It warns when a HorizontalPodAutoscaler targets the workload, since the autoscaler will override a manual replica count. The scale is conditional on the replica count read beforehand, and the result reports the counts before and after.`
+}
+
+func (t *ScaleWorkloadTool) FunctionDefinition() *types.FunctionDefinition {
+	return &types.FunctionDefinition{
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters: &types.Schema{
+			Type: types.TypeObject,
+			Properties: map[string]*types.Schema{
+				"kind": {
+					Type:        types.TypeString,
+					Description: `Workload kind or resource name, e.g. "deployment" or "sts" (default "deployment")`,
+					Default:     "deployment",
+				},
+				"name": {
+					Type:        types.TypeString,
+					Description: "Name of the workload",
+				},
+				"namespace": {
+					Type:        types.TypeString,
+					Description: "Namespace of the workload (defaults to the kubeconfig namespace)",
+				},
+				"replicas": {
+					Type:        types.TypeInteger,
+					Description: "Desired number of replicas",
+					Minimum:     types.Ptr(0.0),
+				},
+			},
+			Required: []string{"name", "replicas"},
+		},
+		Annotations: &types.ToolAnnotations{
+			Title:           "Scale workload",
+			DestructiveHint: true,
+			IdempotentHint:  true,
+			OpenWorldHint:   true,
+		},
+		OutputSchema: types.SchemaFor(&ScaleWorkloadResult{}),
+	}
+}
+
+func (t *ScaleWorkloadTool) Run(ctx context.Context, args map[string]any) (any, error) {
+	kubeconfig, workDir, err := contextPaths(ctx)
+	if err != nil {
+		return &ScaleWorkloadResult{Error: err.Error()}, nil
+	}
+
+	result := &ScaleWorkloadResult{}
+	if err := t.parseArgs(args, result); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	ref := result.Kind + "/" + result.Name
+	nsFlag := ""
+	if result.Namespace != "" {
+		nsFlag = " -n " + result.Namespace
+	}
+
+	current, err := readReplicas(ctx, ref, nsFlag, workDir, kubeconfig)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.Before = &current
+
+	if err := t.checkLimits(current, result.Requested); err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	resource, _ := t.Discovery.Resolve(result.Kind)
+	if warning, err := t.checkHPA(ctx, resource.Kind, result, nsFlag, workDir, kubeconfig); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	} else if warning != "" {
+		result.Warnings = append(result.Warnings, warning)
+	}
+
+	if current == result.Requested {
+		result.After = &current
+		result.Output = fmt.Sprintf("%s already has %d replicas", ref, current)
+		return result, nil
+	}
+
+	// --current-replicas makes the scale fail if another writer changed the
+	// count after it was read.
+	result.Command = fmt.Sprintf("kubectl scale %s%s --replicas=%d --current-replicas=%d", ref, nsFlag, result.Requested, current)
+	execResult, err := RunKubectlCommand(ctx, result.Command, workDir, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	t.Results.Invalidate(result.Namespace)
+	result.Output = strings.TrimSpace(execResult.Stdout)
+	if execResult.Error != "" {
+		result.Error = execResult.Error
+		return result, nil
+	}
+
+	after, err := readReplicas(ctx, ref, nsFlag, workDir, kubeconfig)
+	if err != nil {
+		result.Warnings = append(result.Warnings, "scaled, but reading the new replica count failed: "+err.Error())
+		return result, nil
+	}
+	result.After = &after
+	return result, nil
+}
+
+func (t *ScaleWorkloadTool) IsInteractive(args map[string]any) (bool, error) {
+	return false, nil
+}
+
+// RequiredPermissions checks the default kind; other kinds are checked by the
+// API server when scaled.
+func (t *ScaleWorkloadTool) RequiredPermissions() []types.Permission {
+	return []types.Permission{{Verb: "update", Resource: "deployments.apps", Subresource: "scale"}}
+}
+
+func (t *ScaleWorkloadTool) CheckModifiesResource(args map[string]any) string {
+	return "yes"
+}
+
+func (t *ScaleWorkloadTool) parseArgs(args map[string]any, result *ScaleWorkloadResult) error {
+	kind, err := stringArg(args, "kind")
+	if err != nil {
+		return err
+	}
+	if kind == "" {
+		kind = "deployment"
+	}
+	resource, ok := t.Discovery.Resolve(kind)
+	if group, scalable := scalableResources[resource.Name]; !ok || !scalable || resource.Group() != group {
+		return fmt.Errorf("kind %q cannot be scaled; use a Deployment, StatefulSet, ReplicaSet or ReplicationController", kind)
+	}
+	result.Kind = resource.Name
+
+	if result.Name, err = stringArg(args, "name"); err != nil {
+		return err
+	}
+	if err := validateObjectName(strings.ToLower(resource.Kind), result.Name); err != nil {
+		return err
+	}
+	if result.Namespace, err = stringArg(args, "namespace"); err != nil {
+		return err
+	}
+	if err := validateNamespace(result.Namespace); err != nil {
+		return err
+	}
+
+	replicas, set, err := intArg(args, "replicas")
+	if err != nil {
+		return err
+	}
+	if !set {
+		return fmt.Errorf("replicas is required")
+	}
+	if replicas < 0 {
+		return fmt.Errorf("replicas must not be negative")
+	}
+	result.Requested = replicas
+	return nil
+}
+
+func (t *ScaleWorkloadTool) checkLimits(current, requested int) error {
+	if requested < t.Limits.MinReplicas {
+		return fmt.Errorf("refusing to scale below the configured minimum of %d replicas", t.Limits.MinReplicas)
+	}
+	if t.Limits.MaxReplicas > 0 && requested > t.Limits.MaxReplicas {
+		return fmt.Errorf("refusing to scale above the configured maximum of %d replicas", t.Limits.MaxReplicas)
+	}
+	// A relative change is meaningless when scaling up from zero.
+	if t.Limits.MaxDeltaPercent > 0 && current > 0 {
+		delta := requested - current
+		if delta < 0 {
+			delta = -delta
+		}
+		if delta*100 > current*t.Limits.MaxDeltaPercent {
+			return fmt.Errorf("refusing to change replicas from %d to %d: more than the configured maximum change of %d%%", current, requested, t.Limits.MaxDeltaPercent)
+		}
+	}
+	return nil
+}
+
+// checkHPA returns a warning when a HorizontalPodAutoscaler targets the
+// workload.
+func (t *ScaleWorkloadTool) checkHPA(ctx context.Context, kind string, result *ScaleWorkloadResult, nsFlag, workDir, kubeconfig string) (string, error) {
+	execResult, err := RunKubectlCommand(ctx, "kubectl get horizontalpodautoscalers"+nsFlag+" -o json", workDir, kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	if execResult.Error != "" {
+		return "", fmt.Errorf("could not check for HorizontalPodAutoscalers: %s", execResult.Error)
+	}
+
+	var hpas horizontalPodAutoscalerList
+	if err := json.Unmarshal([]byte(execResult.Stdout), &hpas); err != nil {
+		return "", fmt.Errorf("could not check for HorizontalPodAutoscalers: %v", err)
+	}
+	for _, hpa := range hpas.Items {
+		target := hpa.Spec.ScaleTargetRef
+		if target.Kind != kind || target.Name != result.Name {
+			continue
+		}
+		result.HPA = hpa.Metadata.Name
+		minReplicas := 1
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+		return fmt.Sprintf("HorizontalPodAutoscaler %s manages this workload with %d-%d replicas and will override a manual replica count", hpa.Metadata.Name, minReplicas, hpa.Spec.MaxReplicas), nil
+	}
+	return "", nil
+}
+
+func readReplicas(ctx context.Context, target, nsFlag, workDir, kubeconfig string) (int, error) {
+	execResult, err := RunKubectlCommand(ctx, "kubectl get "+target+nsFlag+" -o json", workDir, kubeconfig)
+	if err != nil {
+		return 0, err
+	}
+	if execResult.Error != "" {
+		return 0, fmt.Errorf("reading %s failed: %s: %s", target, execResult.Error, strings.TrimSpace(execResult.Stdout))
+	}
+
+	var obj scalableObject
+	if err := json.Unmarshal([]byte(execResult.Stdout), &obj); err != nil {
+		return 0, fmt.Errorf("parsing %s: %v", target, err)
+	}
+	// The API server defaults an unset replica count to 1.
+	if obj.Spec.Replicas == nil {
+		return 1, nil
+	}
+	return *obj.Spec.Replicas, nil
+}
@@ -0,0 +1,107 @@
+package kubectl
+
+import (
+	"fmt"
+	"strings"
+
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+// tableBlock is one table of kubectl output with the lines of its rows.
+type tableBlock struct {
+	table types.Table
+	lines []string
+}
+
+// ParseTables parses kubectl's column-aligned table output, including -o wide
+// and -o custom-columns, into rows keyed by column header. Output listing
+// several resource types, such as "kubectl get all", holds one table per
+// type, separated by blank lines.
+func ParseTables(output string) ([]types.Table, error) {
+	blocks, err := parseTableBlocks(output)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]types.Table, len(blocks))
+	for i, block := range blocks {
+		tables[i] = block.table
+	}
+	return tables, nil
+}
+
+func parseTableBlocks(output string) ([]tableBlock, error) {
+	var blocks []tableBlock
+	var columns []tableColumn
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			columns = nil
+			continue
+		}
+		if columns == nil {
+			if columns = tableColumns(line); columns == nil {
+				return nil, fmt.Errorf("output is not a table: unexpected header %q", line)
+			}
+			block := tableBlock{table: types.Table{Rows: []map[string]string{}}}
+			for _, column := range columns {
+				block.table.Columns = append(block.table.Columns, column.name)
+			}
+			blocks = append(blocks, block)
+			continue
+		}
+
+		block := &blocks[len(blocks)-1]
+		block.table.Rows = append(block.table.Rows, tableRow(line, columns))
+		block.lines = append(block.lines, line)
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("output is not a table: no header")
+	}
+	return blocks, nil
+}
+
+type tableColumn struct {
+	name  string
+	start int
+}
+
+// tableColumns finds the columns of a header line. kubectl pads columns with
+// at least three spaces, so single spaces, as in "NOMINATED NODE", belong to
+// the column name. Offsets count runes, as kubectl's tabwriter does.
+func tableColumns(header string) []tableColumn {
+	runes := []rune(header)
+	if len(runes) == 0 || runes[0] == ' ' {
+		return nil
+	}
+
+	var columns []tableColumn
+	for i, r := range runes {
+		if r != ' ' && (i == 0 || (i >= 2 && runes[i-1] == ' ' && runes[i-2] == ' ')) {
+			columns = append(columns, tableColumn{start: i})
+		}
+	}
+	for i := range columns {
+		end := len(runes)
+		if i+1 < len(columns) {
+			end = columns[i+1].start
+		}
+		columns[i].name = strings.TrimSpace(string(runes[columns[i].start:end]))
+	}
+	return columns
+}
+
+func tableRow(line string, columns []tableColumn) map[string]string {
+	runes := []rune(line)
+	row := make(map[string]string, len(columns))
+	for i, column := range columns {
+		if column.start >= len(runes) {
+			row[column.name] = ""
+			continue
+		}
+		end := len(runes)
+		if i+1 < len(columns) && columns[i+1].start < len(runes) {
+			end = columns[i+1].start
+		}
+		row[column.name] = strings.TrimSpace(string(runes[column.start:end]))
+	}
+	return row
+}
@@ -1,30 +1,55 @@
 package kubectl
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"kubectl-go-mcp-server/internal/config"
 	"kubectl-go-mcp-server/pkg/types"
 )
 
-type KubectlTool struct{}
+type KubectlTool struct {
+	Discovery *DiscoveryCache
+	// ReadOnly rejects commands other than those IsReadOnlyCommand allows.
+	ReadOnly     bool
+	OutputLimits types.OutputLimits
+	// Pages, if set, stores output over OutputLimits for fetch_output
+	// instead of truncating it.
+	Pages *OutputStore
+	// Normalizer, if set, cleans up -o yaml and -o json output.
+	Normalizer *Normalizer
+	// Results, if set, caches the results of read-only commands and is
+	// invalidated by commands that modify resources.
+	Results *ResultCache
+	// MaxProcessBytes bounds the stdout kept whole for normalizing,
+	// filtering or table parsing. Zero means processBytesFactor times
+	// OutputLimits.MaxBytes.
+	MaxProcessBytes int
+}
 
 func (t *KubectlTool) Name() string {
 	return "kubectl"
 }
 
 func (t *KubectlTool) Description() string {
-	return `Execute kubectl commands to interact with your Kubernetes cluster. Use this tool to query cluster state, manage resources, and perform administrative tasks.
+	description := `Execute kubectl commands to interact with your Kubernetes cluster. Use this tool to query cluster state, manage resources, and perform administrative tasks.
 
 Note: Interactive commands (kubectl exec -it, kubectl edit, kubectl port-forward) are not supported. Use non-interactive alternatives instead.
 
 Examples: kubectl get pods, kubectl describe deployment my-app, kubectl logs my-pod, kubectl exec my-pod -- ps aux`
+	if t.ReadOnly {
+		description += "\n\nThe server is in read-only mode: only get, describe, logs, top, explain, api-resources, version, " +
+			"auth can-i and config view, current-context and get-contexts are allowed."
+	}
+	return description
 }
 
 func (t *KubectlTool) FunctionDefinition() *types.FunctionDefinition {
@@ -47,30 +72,52 @@ Note: Interactive commands (exec -it, edit, port-forward) are not supported.`,
 			}, "modifies_resource": {
 				Type:        types.TypeString,
 				Description: `Whether the command modifies cluster resources: "yes", "no", or "unknown"`,
+				Enum:        []any{"yes", "no", "unknown"},
+			}, "filter": {
+				Type: types.TypeString,
+				Description: `A jq expression applied to the command's JSON output; each value it produces is returned as JSON, like jq. Only for get, version and config view, which run with -o json unless they set -o json themselves.
+
+Examples:
+• .items[].metadata.name
+• .items[] | select(.status.phase != "Running") | {name: .metadata.name, phase: .status.phase}
+• [.items[].spec.containers[].image] | unique
+• .items[] | select(.spec.nodeName == "node-1") | .metadata.name`,
+			}, "grep": {
+				Type:        types.TypeString,
+				Description: `Go regular expression; only output lines that match are returned, like "kubectl logs my-pod | grep -E ERROR". Use (?i) for case-insensitive matching, e.g. "(?i)error|warn"`,
+			}, "grep_invert": {
+				Type:        types.TypeBoolean,
+				Description: "Return the lines that do not match grep instead, like grep -v",
+			}, "context_lines": {
+				Type:        types.TypeInteger,
+				Description: "Lines of context to return before and after each match, like grep -C",
+				Minimum:     types.Ptr(0.0),
+				Maximum:     types.Ptr(float64(maxGrepContextLines)),
+			}, "max_matches": {
+				Type:        types.TypeInteger,
+				Description: "Stop after this many matching lines, like grep -m",
+				Minimum:     types.Ptr(1.0),
+			}, "parse_table": {
+				Type:        types.TypeBoolean,
+				Description: "Also return table output (the default, -o wide or -o custom-columns) as rows keyed by column header, for reliable sorting and filtering",
 			},
 			},
 			Required: []string{"command"},
 		},
+		Annotations: &types.ToolAnnotations{
+			Title:           "Run kubectl command",
+			ReadOnlyHint:    t.ReadOnly,
+			DestructiveHint: !t.ReadOnly,
+			OpenWorldHint:   true,
+		},
+		OutputSchema: types.SchemaFor(&types.ExecResult{}),
 	}
 }
 
 func (t *KubectlTool) Run(ctx context.Context, args map[string]any) (any, error) {
-	kubeconfigVal := ctx.Value(types.KubeconfigKey)
-	if kubeconfigVal == nil {
-		return &types.ExecResult{Error: "kubeconfig not provided in context"}, nil
-	}
-	kubeconfig, ok := kubeconfigVal.(string)
-	if !ok {
-		return &types.ExecResult{Error: "kubeconfig must be a string"}, nil
-	}
-
-	workDirVal := ctx.Value(types.WorkdirKey)
-	if workDirVal == nil {
-		return &types.ExecResult{Error: "workdir not provided in context"}, nil
-	}
-	workDir, ok := workDirVal.(string)
-	if !ok {
-		return &types.ExecResult{Error: "workdir must be a string"}, nil
+	kubeconfig, workDir, err := contextPaths(ctx)
+	if err != nil {
+		return &types.ExecResult{Error: err.Error()}, nil
 	}
 
 	commandVal, ok := args["command"]
@@ -87,7 +134,204 @@ func (t *KubectlTool) Run(ctx context.Context, args map[string]any) (any, error)
 		return &types.ExecResult{Error: fmt.Sprintf("Security violation: %s", err.Error())}, nil
 	}
 
-	return RunKubectlCommand(ctx, command, workDir, kubeconfig)
+	if t.ReadOnly && !IsReadOnlyCommand(command) {
+		return &types.ExecResult{Command: command, Error: "Read-only mode: only commands that read cluster state are allowed"}, nil
+	}
+
+	if t.Discovery != nil {
+		if err := t.Discovery.CheckCommand(command); err != nil {
+			return &types.ExecResult{Command: command, Error: fmt.Sprintf("Validation failed: %s", err.Error())}, nil
+		}
+	}
+
+	command, pipeline, err := t.outputPipeline(command, args)
+	if err != nil {
+		return &types.ExecResult{Command: command, Error: err.Error()}, nil
+	}
+	opts := runOptions{pages: t.Pages}
+	switch {
+	case pipeline == nil:
+	case pipeline.grepOnly():
+		opts.grep = pipeline.grep
+	default:
+		opts.process, opts.maxProcessBytes = pipeline.process, t.maxProcessBytes()
+	}
+
+	parsed := parseCommand(command)
+	modifies := ModifiesResource(command)
+	cacheKey := ""
+	if modifies == "no" && parsed.cacheable() {
+		cacheKey = resultCacheKey(kubeconfig, parsed, args)
+		if result, ok := t.Results.Get(cacheKey); ok {
+			return result, nil
+		}
+	}
+
+	ctx = context.WithValue(ctx, types.OutputLimitsKey, t.OutputLimits)
+	result, err := runKubectlCommand(ctx, command, workDir, kubeconfig, opts)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline != nil {
+		result.Normalized = pipeline.normalized
+		result.Tables = pipeline.tables
+	}
+
+	switch {
+	case parsed.verb == "config":
+		// config may switch the current context or edit the kubeconfig.
+		t.Results.Clear()
+	case modifies != "no":
+		// Even failed commands may have changed part of what they targeted.
+		t.Results.Invalidate(parsed.namespace())
+	case cacheKey != "" && result.Error == "":
+		t.Results.Put(cacheKey, parsed.namespace(), result)
+	}
+	return result, nil
+}
+
+// outputPipeline processes the stdout of a successful command: objects are
+// normalized, unless a filter is applied to them instead, then grep is
+// applied.
+type outputPipeline struct {
+	normalizer *Normalizer
+	isYAML     bool
+	filter     *Filter
+	grep       *LineGrep
+	parseTable bool
+
+	// normalized and tables are set by process.
+	normalized *types.NormalizeStats
+	tables     []types.Table
+}
+
+// grepOnly reports whether grep is the only processing, which can then be
+// applied to stdout as it is written.
+func (p *outputPipeline) grepOnly() bool {
+	return p.grep != nil && p.normalizer == nil && p.filter == nil && !p.parseTable
+}
+
+// maxProcessBytes returns the stdout kept whole for processing.
+func (t *KubectlTool) maxProcessBytes() int {
+	switch {
+	case t.MaxProcessBytes > 0:
+		return t.MaxProcessBytes
+	case t.OutputLimits.MaxBytes > 0:
+		return processBytesFactor * t.OutputLimits.MaxBytes
+	default:
+		return unlimitedProcessBytes
+	}
+}
+
+// outputPipeline returns the command to run and the processing of its output
+// that the tool's normalizer and the filter and grep arguments ask for, or a
+// nil pipeline if there is nothing to do.
+func (t *KubectlTool) outputPipeline(command string, args map[string]any) (string, *outputPipeline, error) {
+	filterExpr, err := stringArg(args, "filter")
+	if err != nil {
+		return command, nil, err
+	}
+	grep, err := grepArgs(args)
+	if err != nil {
+		return command, nil, err
+	}
+
+	parseTable, err := boolArg(args, "parse_table")
+	if err != nil {
+		return command, nil, err
+	}
+
+	pipeline := &outputPipeline{grep: grep, parseTable: parseTable}
+	if parseTable {
+		switch format, _, _ := strings.Cut(outputFormat(command), "="); format {
+		case "", "wide", "custom-columns":
+		default:
+			return command, nil, fmt.Errorf("parse_table needs table output: use the default output, -o wide or -o custom-columns")
+		}
+		if filterExpr != "" {
+			return command, nil, fmt.Errorf("parse_table cannot be combined with filter, which returns JSON")
+		}
+	}
+	if filterExpr != "" {
+		if pipeline.filter, err = ParseFilter(filterExpr); err != nil {
+			return command, nil, err
+		}
+		if !printsJSON(parseCommand(command)) {
+			return command, nil, fmt.Errorf("filter needs a command that prints JSON: get, version or config view")
+		}
+		switch outputFormat(command) {
+		case "":
+			command += " -o json"
+		case "json":
+		default:
+			return command, nil, fmt.Errorf("filter needs JSON output: use -o json or leave out -o")
+		}
+	}
+	// A filter selects fields itself, including those normalization strips.
+	if t.Normalizer != nil && pipeline.filter == nil {
+		switch outputFormat(command) {
+		case "json":
+			pipeline.normalizer = t.Normalizer
+		case "yaml":
+			pipeline.normalizer, pipeline.isYAML = t.Normalizer, true
+		}
+	}
+
+	if pipeline.normalizer == nil && pipeline.filter == nil && pipeline.grep == nil && !pipeline.parseTable {
+		return command, nil, nil
+	}
+	return command, pipeline, nil
+}
+
+func (p *outputPipeline) process(stdout []byte) ([]byte, error) {
+	if p.normalizer != nil {
+		stdout, p.normalized = p.normalizer.Normalize(stdout, p.isYAML)
+	}
+	if p.filter != nil {
+		var err error
+		if stdout, err = p.filter.Apply(stdout); err != nil {
+			return nil, err
+		}
+	}
+	// No rows is not an error; "No resources found" goes to stderr.
+	if p.parseTable && len(bytes.TrimSpace(stdout)) > 0 {
+		blocks, err := parseTableBlocks(string(stdout))
+		if err != nil {
+			return nil, fmt.Errorf("parse_table: %w", err)
+		}
+		for _, block := range blocks {
+			if p.grep != nil {
+				block.table.Rows = p.grep.tableRows(block)
+			}
+			p.tables = append(p.tables, block.table)
+		}
+	}
+	if p.grep != nil {
+		return p.grep.Apply(stdout)
+	}
+	return stdout, nil
+}
+
+func contextPaths(ctx context.Context) (kubeconfig, workDir string, err error) {
+	kubeconfigVal := ctx.Value(types.KubeconfigKey)
+	if kubeconfigVal == nil {
+		return "", "", fmt.Errorf("kubeconfig not provided in context")
+	}
+	kubeconfig, ok := kubeconfigVal.(string)
+	if !ok {
+		return "", "", fmt.Errorf("kubeconfig must be a string")
+	}
+
+	workDirVal := ctx.Value(types.WorkdirKey)
+	if workDirVal == nil {
+		return "", "", fmt.Errorf("workdir not provided in context")
+	}
+	workDir, ok = workDirVal.(string)
+	if !ok {
+		return "", "", fmt.Errorf("workdir must be a string")
+	}
+
+	return kubeconfig, workDir, nil
 }
 
 func (t *KubectlTool) IsInteractive(args map[string]any) (bool, error) {
@@ -183,16 +427,38 @@ func checkForCommandInjection(command string) error {
 		}
 	}
 
-	allPatterns := append(dangerousPatterns, "bash", "sh", "/bin/", "python", "perl", "ruby", "node")
-	for _, pattern := range allPatterns {
+	for _, pattern := range shellMetacharacters {
 		if strings.Contains(lowerCommand, pattern) {
 			return fmt.Errorf("command contains potentially dangerous pattern: %s", pattern)
 		}
 	}
 
+	// Program names are matched as whole words so that resource names and
+	// flags such as "nodes" or "spec.nodeName" are not mistaken for them.
+	for _, word := range strings.Fields(lowerCommand) {
+		if blockedPrograms[filepath.Base(word)] {
+			return fmt.Errorf("command contains potentially dangerous pattern: %s", word)
+		}
+	}
+
 	return nil
 }
 
+var shellMetacharacters = []string{
+	";", "&&", "||", "|", "`", "$(", "${", ">/", "<", ">>", "<<",
+	"&", "\n", "\r", "/bin/",
+}
+
+// blockedPrograms are programs a command may not name. node is not among
+// them because "kubectl get node worker-1" names the resource; with the
+// shell metacharacters above rejected, kubectl cannot start it anyway.
+var blockedPrograms = map[string]bool{
+	"curl": true, "wget": true, "nc": true, "netcat": true,
+	"rm": true, "mv": true, "cp": true, "chmod": true, "chown": true,
+	"sudo": true, "su": true, "bash": true, "sh": true,
+	"python": true, "python3": true, "perl": true, "ruby": true,
+}
+
 func isValidKubectlSubcommand(subcommand string) bool {
 	allowedSubcommands := map[string]bool{
 		"get":      true,
@@ -234,6 +500,23 @@ func isValidKubectlSubcommand(subcommand string) bool {
 }
 
 func RunKubectlCommand(ctx context.Context, command, workDir, kubeconfig string) (*types.ExecResult, error) {
+	return runKubectlCommand(ctx, command, workDir, kubeconfig, runOptions{})
+}
+
+// runOptions changes how runKubectlCommand handles output.
+type runOptions struct {
+	// pages, if set, keeps output over the limits for paging instead of
+	// truncating it.
+	pages *OutputStore
+	// process, if set, rewrites stdout of a successful command, kept whole
+	// up to maxProcessBytes, before the limits are applied.
+	process         func(stdout []byte) ([]byte, error)
+	maxProcessBytes int
+	// grep, if set, selects the lines of stdout as they are written.
+	grep *LineGrep
+}
+
+func runKubectlCommand(ctx context.Context, command, workDir, kubeconfig string, opts runOptions) (*types.ExecResult, error) {
 	if err := ValidateKubectlCommand(command); err != nil {
 		return &types.ExecResult{Error: fmt.Sprintf("Security validation failed: %s", err.Error())}, nil
 	}
@@ -242,16 +525,64 @@ func RunKubectlCommand(ctx context.Context, command, workDir, kubeconfig string)
 		return &types.ExecResult{Error: err.Error()}, nil
 	}
 
+	cmd, err := kubectlCmd(ctx, command, workDir, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	result, err := executeCommand(ctx, cmd, opts)
+	if err != nil || result.Metadata == nil {
+		return result, err
+	}
+	// Classify the command as given; the one run may name the pinned kubectl
+	// by another file name.
+	result.Metadata.ModifiesResource = ModifiesResource(command)
+	kubeContext, namespace := commandScope(kubeconfig, parseCommand(command))
+	result.Metadata.Context = kubeContext
+	if namespace == "*" {
+		result.Metadata.AllNamespaces = true
+	} else {
+		result.Metadata.Namespace = namespace
+	}
+	return result, nil
+}
+
+// StartKubectlCommand starts a long-running kubectl command, such as a watch,
+// and returns it with its stdout. Stderr is discarded. The process is killed
+// when ctx is cancelled; the caller must Wait for it.
+func StartKubectlCommand(ctx context.Context, command, workDir, kubeconfig string) (*exec.Cmd, io.ReadCloser, error) {
+	if err := ValidateKubectlCommand(command); err != nil {
+		return nil, nil, fmt.Errorf("security validation failed: %w", err)
+	}
+	if isInteractive, err := IsInteractiveCommand(command); isInteractive {
+		return nil, nil, err
+	}
+
+	cmd, err := kubectlCmd(ctx, command, workDir, kubeconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+	return cmd, stdout, nil
+}
+
+func kubectlCmd(ctx context.Context, command, workDir, kubeconfig string) (*exec.Cmd, error) {
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(ctx, os.Getenv("COMSPEC"), "/c", command)
+		cmd = exec.CommandContext(ctx, os.Getenv("COMSPEC"), "/c", withPinnedBinary(command))
 	} else {
-		cmd = exec.CommandContext(ctx, LookupBashBin(), "-c", command)
+		cmd = exec.CommandContext(ctx, LookupBashBin(), "-c", withPinnedBinary(command))
 	}
-	cmd.Env = os.Environ()
+	cmd.Env = ChildEnv()
 	cmd.Dir = workDir
-
-	cmd.Env = removeEnvVar(cmd.Env, "KUBECONFIG")
+	// bash may leave kubectl running briefly after it is killed; don't let
+	// Wait block on the pipe it still holds.
+	cmd.WaitDelay = time.Second
 
 	if kubeconfig != "" {
 		expandedKubeconfig, err := config.ValidateKubeconfigPath(kubeconfig)
@@ -260,8 +591,7 @@ func RunKubectlCommand(ctx context.Context, command, workDir, kubeconfig string)
 		}
 		cmd.Env = append(cmd.Env, "KUBECONFIG="+expandedKubeconfig)
 	}
-
-	return executeCommand(cmd)
+	return cmd, nil
 }
 
 func IsInteractiveCommand(command string) (bool, error) {
@@ -284,6 +614,40 @@ func IsInteractiveCommand(command string) (bool, error) {
 	return false, nil
 }
 
+// readOnlyVerbs are the commands read-only mode allows; of the auth and
+// config commands only readOnlySubcommands are allowed.
+var readOnlyVerbs = map[string]bool{
+	"get": true, "describe": true, "logs": true, "top": true,
+	"explain": true, "api-resources": true, "version": true,
+}
+
+var readOnlySubcommands = map[string]bool{
+	"auth can-i":             true,
+	"config view":            true,
+	"config current-context": true,
+	"config get-contexts":    true,
+}
+
+// IsReadOnlyCommand reports whether command only reads cluster state or the
+// kubeconfig. Unlike ModifiesResource's "no", it excludes exec, port-forward,
+// proxy and the config commands that change the kubeconfig.
+func IsReadOnlyCommand(command string) bool {
+	words := strings.Fields(command)
+	if len(words) < 2 || filepath.Base(words[0]) != "kubectl" {
+		return false
+	}
+	parsed := parseCommand(command)
+	if readOnlyVerbs[parsed.verb] {
+		return true
+	}
+	if len(parsed.positional) == 0 || !readOnlySubcommands[parsed.verb+" "+parsed.positional[0]] {
+		return false
+	}
+	// config view --raw prints the kubeconfig's credentials.
+	raw, ok := parsed.flags["--raw"]
+	return !ok || raw == "false"
+}
+
 func ModifiesResource(command string) string {
 	words := strings.Fields(command)
 	if len(words) < 2 {
@@ -300,6 +664,8 @@ func ModifiesResource(command string) string {
 		return "no"
 	case "create", "apply", "delete", "patch", "replace", "scale", "rollout", "annotate", "label":
 		return "yes"
+	case "cordon", "uncordon", "drain", "taint":
+		return "yes"
 	case "exec", "port-forward", "proxy":
 		return "no"
 	default:
@@ -307,17 +673,78 @@ func ModifiesResource(command string) string {
 	}
 }
 
-func executeCommand(cmd *exec.Cmd) (*types.ExecResult, error) {
-	command := strings.Join(cmd.Args, " ")
+func executeCommand(ctx context.Context, cmd *exec.Cmd, opts runOptions) (*types.ExecResult, error) {
+	argv := append([]string(nil), cmd.Args...)
+	if len(argv) > 0 {
+		argv[len(argv)-1] = MaskSecrets(argv[len(argv)-1])
+	}
+	command := strings.Join(argv, " ")
 
 	if isInteractive, err := IsInteractiveCommand(command); isInteractive {
 		return &types.ExecResult{Command: command, Error: err.Error()}, nil
 	}
 
-	output, err := cmd.CombinedOutput()
-	result := &types.ExecResult{
-		Command: command,
-		Stdout:  string(output),
+	limits, _ := ctx.Value(types.OutputLimitsKey).(types.OutputLimits)
+	var sink outputSink = newLimitedBuffer(limits)
+	if opts.pages != nil {
+		sink = newSpoolWriter(opts.pages, limits)
+	}
+
+	// Output to be processed needs stdout apart from stderr, which is
+	// appended after it.
+	var stdout io.Writer = sink
+	var captured *cappedBuffer
+	var grep *grepWriter
+	var stderr *limitedBuffer
+	switch {
+	case opts.process != nil:
+		captured = &cappedBuffer{limit: opts.maxProcessBytes}
+		stdout = captured
+	case opts.grep != nil:
+		grep = opts.grep.writer(sink)
+		stdout = grep
+	}
+	if stdout != io.Writer(sink) {
+		stderr = newLimitedBuffer(limits)
+		cmd.Stderr = stderr
+	}
+	var progress *progressWriter
+	if report, ok := ctx.Value(types.ProgressKey).(types.ProgressFunc); ok && report != nil {
+		progress = &progressWriter{report: report, next: stdout}
+		stdout = progress
+	}
+	cmd.Stdout = stdout
+	if stderr == nil {
+		cmd.Stderr = stdout
+	}
+	start := time.Now()
+	err := cmd.Run()
+	duration := time.Since(start)
+	if progress != nil {
+		progress.flush()
+	}
+
+	result := &types.ExecResult{Command: command}
+	result.Metadata = &types.ExecMetadata{
+		StartTime:  start.UTC(),
+		DurationMs: duration.Milliseconds(),
+		Argv:       argv,
+	}
+	result.Metadata.KubectlVersion, _ = ctx.Value(types.KubectlVersionKey).(string)
+	var processErr error
+	if captured != nil {
+		processErr = writeProcessed(sink, captured, opts.process, err == nil)
+	}
+	if grep != nil {
+		grep.flush()
+	}
+	if stderr != nil {
+		output, _ := stderr.Output()
+		io.WriteString(sink, output)
+	}
+	sink.finish(result)
+	if processErr != nil {
+		result.Error = processErr.Error()
 	}
 
 	if err != nil {
@@ -325,6 +752,9 @@ func executeCommand(cmd *exec.Cmd) (*types.ExecResult, error) {
 			result.ExitCode = exitError.ExitCode()
 		}
 		result.Error = err.Error()
+		if ctx.Err() != nil {
+			result.Error = fmt.Sprintf("command cancelled: %v", ctx.Err())
+		}
 	}
 
 	return result, nil
@@ -0,0 +1,103 @@
+package kubectl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const serverVersionTTL = 10 * time.Minute
+
+type VersionInfo struct {
+	GitVersion string `json:"gitVersion"`
+	Major      string `json:"major"`
+	Minor      string `json:"minor"`
+}
+
+type KubectlVersion struct {
+	ClientVersion *VersionInfo `json:"clientVersion,omitempty"`
+	ServerVersion *VersionInfo `json:"serverVersion,omitempty"`
+}
+
+// ParseKubectlVersion parses the output of "kubectl version -o json". kubectl
+// prints warnings (e.g. about version skew) after the JSON document, so only
+// the first document is decoded.
+func ParseKubectlVersion(output string) (*KubectlVersion, error) {
+	start := strings.Index(output, "{")
+	if start < 0 {
+		return nil, fmt.Errorf("unexpected kubectl version output: %q", strings.TrimSpace(output))
+	}
+
+	var version KubectlVersion
+	if err := json.NewDecoder(strings.NewReader(output[start:])).Decode(&version); err != nil {
+		return nil, fmt.Errorf("parsing kubectl version output: %w", err)
+	}
+	return &version, nil
+}
+
+type serverVersionEntry struct {
+	version   string
+	fetchedAt time.Time
+}
+
+// serverVersions caches the cluster version per kubeconfig and current
+// context so that callers keying caches on it don't fork kubectl for every
+// lookup.
+type serverVersions struct {
+	mu      sync.Mutex
+	entries map[string]serverVersionEntry
+}
+
+func (s *serverVersions) Get(ctx context.Context, workDir, kubeconfig string) (string, error) {
+	key := kubeconfig + "|" + currentContext(kubeconfig)
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	s.mu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < serverVersionTTL {
+		return entry.version, nil
+	}
+
+	result, err := RunKubectlCommand(ctx, "kubectl version -o json", workDir, kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	version, err := ParseKubectlVersion(result.Stdout)
+	if err != nil {
+		return "", err
+	}
+	if version.ServerVersion == nil || version.ServerVersion.GitVersion == "" {
+		return "", fmt.Errorf("cluster version unavailable: %s", strings.TrimSpace(result.Stdout))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]serverVersionEntry)
+	}
+	s.entries[key] = serverVersionEntry{version: version.ServerVersion.GitVersion, fetchedAt: time.Now()}
+	return version.ServerVersion.GitVersion, nil
+}
+
+// MinorVersionSkew returns how many minor versions server is ahead of client,
+// or behind it if negative. Minor versions such as "28+" are accepted.
+func MinorVersionSkew(client, server *VersionInfo) (int, error) {
+	if client == nil || server == nil {
+		return 0, fmt.Errorf("version unavailable")
+	}
+	if client.Major != server.Major {
+		return 0, fmt.Errorf("major versions differ: %s and %s", client.GitVersion, server.GitVersion)
+	}
+	clientMinor, err := strconv.Atoi(strings.TrimRight(client.Minor, "+"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid minor version %q", client.Minor)
+	}
+	serverMinor, err := strconv.Atoi(strings.TrimRight(server.Minor, "+"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid minor version %q", server.Minor)
+	}
+	return serverMinor - clientMinor, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 type contextKey string
@@ -11,8 +12,29 @@ type contextKey string
 const (
 	KubeconfigKey contextKey = "kubeconfig"
 	WorkdirKey    contextKey = "workdir"
+
+	// ProgressKey holds a ProgressFunc when the client asked for progress
+	// notifications.
+	ProgressKey contextKey = "progress"
+
+	// OutputLimitsKey holds the OutputLimits applied to command output.
+	OutputLimitsKey contextKey = "outputLimits"
+
+	// KubectlVersionKey holds the kubectl client version, as reported by
+	// "kubectl version --client", recorded in ExecMetadata.
+	KubectlVersionKey contextKey = "kubectlVersion"
 )
 
+// OutputLimits caps the output kept from a command; the head and tail are
+// kept and the middle dropped. Zero means unlimited.
+type OutputLimits struct {
+	MaxBytes int
+	MaxLines int
+}
+
+// ProgressFunc reports one line of output from a running command.
+type ProgressFunc func(message string)
+
 type Tool interface {
 	Name() string
 	Description() string
@@ -22,10 +44,41 @@ type Tool interface {
 	CheckModifiesResource(args map[string]any) string
 }
 
+// PermissionedTool is implemented by tools that are only useful when the
+// current context may perform certain actions; the server hides them
+// otherwise.
+type PermissionedTool interface {
+	RequiredPermissions() []Permission
+}
+
+// Permission is an action as checked by "kubectl auth can-i <verb> <resource>
+// --subresource=<subresource>".
+type Permission struct {
+	Verb        string
+	Resource    string
+	Subresource string
+}
+
 type FunctionDefinition struct {
-	Name        string  `json:"name,omitempty"`
-	Description string  `json:"description,omitempty"`
-	Parameters  *Schema `json:"parameters,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Parameters  *Schema          `json:"parameters,omitempty"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+	// OutputSchema describes the result Run returns; when set, results are
+	// also sent to clients as structured content.
+	OutputSchema *Schema `json:"outputSchema,omitempty"`
+}
+
+// ToolAnnotations are hints that let clients decide when to ask the user for
+// confirmation. DestructiveHint and IdempotentHint only matter when
+// ReadOnlyHint is false; a tool that cannot modify anything is never listed
+// as destructive. OpenWorldHint marks tools that call the cluster.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    bool   `json:"readOnlyHint"`
+	DestructiveHint bool   `json:"destructiveHint"`
+	IdempotentHint  bool   `json:"idempotentHint"`
+	OpenWorldHint   bool   `json:"openWorldHint"`
 }
 
 type Schema struct {
@@ -34,6 +87,28 @@ type Schema struct {
 	Items       *Schema            `json:"items,omitempty"`
 	Description string             `json:"description,omitempty"`
 	Required    []string           `json:"required,omitempty"`
+
+	Enum    []any    `json:"enum,omitempty"`
+	Default any      `json:"default,omitempty"`
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+	// AdditionalProperties set to false rejects object properties that are
+	// not declared in Properties.
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+	// Nullable also allows null; the type is then written as [type, "null"].
+	Nullable bool `json:"-"`
+}
+
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	if !s.Nullable || s.Type == "" {
+		return json.Marshal(plain(s))
+	}
+	return json.Marshal(struct {
+		Type []SchemaType `json:"type"`
+		plain
+	}{[]SchemaType{s.Type, "null"}, plain(s)})
 }
 
 type SchemaType string
@@ -44,8 +119,14 @@ const (
 	TypeString  SchemaType = "string"
 	TypeBoolean SchemaType = "boolean"
 	TypeInteger SchemaType = "integer"
+	TypeNumber  SchemaType = "number"
 )
 
+// Ptr returns a pointer to v, for optional schema keywords such as Minimum.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
 func (s *Schema) ToRawSchema() (json.RawMessage, error) {
 	return json.Marshal(s)
 }
@@ -57,6 +138,49 @@ type ExecResult struct {
 	Stderr     string `json:"stderr,omitempty"`
 	ExitCode   int    `json:"exit_code,omitempty"`
 	StreamType string `json:"stream_type,omitempty"`
+	// Truncated is set when output beyond the OutputLimits was dropped;
+	// TotalBytes is the size before truncation.
+	Truncated  bool   `json:"truncated,omitempty"`
+	TotalBytes int64  `json:"total_bytes,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+	// NextCursor is set when output over the limits was stored; pass it to
+	// fetch_output for the next page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Normalized reports what output normalization removed.
+	Normalized *NormalizeStats `json:"normalized,omitempty"`
+	// Tables holds table output parsed into rows, when asked for.
+	Tables []Table `json:"tables,omitempty"`
+	// Cached is set when the result was served from the result cache.
+	Cached bool `json:"cached,omitempty"`
+	// Metadata records when, where and how the command ran.
+	Metadata *ExecMetadata `json:"metadata,omitempty"`
+}
+
+// ExecMetadata is the provenance of a command's result. Argv is the exact
+// argument list executed, with secret values masked.
+type ExecMetadata struct {
+	StartTime        time.Time `json:"start_time"`
+	DurationMs       int64     `json:"duration_ms"`
+	Context          string    `json:"context,omitempty"`
+	Namespace        string    `json:"namespace,omitempty"`
+	AllNamespaces    bool      `json:"all_namespaces,omitempty"`
+	KubectlVersion   string    `json:"kubectl_version,omitempty"`
+	Argv             []string  `json:"argv"`
+	ModifiesResource string    `json:"modifies_resource"`
+}
+
+// Table is kubectl table output with each row keyed by column header.
+type Table struct {
+	Columns []string            `json:"columns"`
+	Rows    []map[string]string `json:"rows"`
+}
+
+// NormalizeStats describes the effect of normalizing command output.
+type NormalizeStats struct {
+	StrippedFields       int `json:"stripped_fields"`
+	BytesBefore          int `json:"bytes_before"`
+	BytesAfter           int `json:"bytes_after"`
+	EstimatedTokensSaved int `json:"estimated_tokens_saved"`
 }
 
 func (e *ExecResult) String() string {
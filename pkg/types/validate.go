@@ -0,0 +1,202 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Validate checks a value decoded from JSON, such as tool call arguments,
+// against the schema. The error names the first offending argument. A nil
+// schema accepts everything, and null values are treated as absent.
+func (s *Schema) Validate(value any) error {
+	return s.validate("", value)
+}
+
+func (s *Schema) validate(path string, value any) error {
+	if s == nil || value == nil {
+		return nil
+	}
+
+	if err := s.validateType(value); err != nil {
+		return pathError(path, err)
+	}
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		return pathError(path, fmt.Errorf("must be one of %s", formatEnum(s.Enum)))
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				return pathError(path, fmt.Errorf("schema has invalid pattern %q: %w", s.Pattern, err))
+			}
+			if !re.MatchString(v) {
+				return pathError(path, fmt.Errorf("must match pattern %q", s.Pattern))
+			}
+		}
+	case map[string]any:
+		return s.validateObject(path, v)
+	case []any:
+		for i, item := range v {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	default:
+		if n, ok := number(value); ok {
+			if s.Minimum != nil && n < *s.Minimum {
+				return pathError(path, fmt.Errorf("must be at least %v", *s.Minimum))
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				return pathError(path, fmt.Errorf("must be at most %v", *s.Maximum))
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateType(value any) error {
+	var ok bool
+	switch s.Type {
+	case "":
+		return nil
+	case TypeString:
+		_, ok = value.(string)
+	case TypeBoolean:
+		_, ok = value.(bool)
+	case TypeObject:
+		_, ok = value.(map[string]any)
+	case TypeArray:
+		_, ok = value.([]any)
+	case TypeNumber:
+		_, ok = number(value)
+	case TypeInteger:
+		n, isNumber := number(value)
+		if isNumber && n != math.Trunc(n) {
+			return fmt.Errorf("must be an integer, got %v", n)
+		}
+		ok = isNumber
+	}
+	if !ok {
+		return fmt.Errorf("must be %s %s, got %s", article(s.Type), s.Type, jsonTypeName(value))
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(path string, obj map[string]any) error {
+	for _, name := range s.Required {
+		if obj[name] == nil {
+			return pathError(joinPath(path, name), fmt.Errorf("is required"))
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, declared := s.Properties[name]
+		if !declared {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return pathError(joinPath(path, name), fmt.Errorf("is not a known argument"))
+			}
+			continue
+		}
+		if err := property.validate(joinPath(path, name), obj[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) inEnum(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	n, isNumber := number(value)
+	for _, allowed := range s.Enum {
+		if m, ok := number(allowed); ok && isNumber {
+			if n == m {
+				return true
+			}
+		} else if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// number converts the numeric types that JSON decoding or Go callers produce.
+func number(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	if _, ok := number(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func article(t SchemaType) string {
+	if t == TypeObject || t == TypeArray || t == TypeInteger {
+		return "an"
+	}
+	return "a"
+}
+
+func formatEnum(values []any) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			quoted[i] = fmt.Sprintf("%q", s)
+		} else {
+			quoted[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func pathError(path string, err error) error {
+	if path == "" {
+		return fmt.Errorf("arguments %w", err)
+	}
+	return fmt.Errorf("argument %q %w", path, err)
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+// fakeKubectlVersion answers "kubectl version" like kubectl v1.30.2 without a
+// cluster.
+const fakeKubectlVersion = `case "$1" in version) echo '{"clientVersion": {"major": "1", "minor": "30", "gitVersion": "v1.30.2"}}';; esac`
+
+func TestResolveBinary(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Found on PATH", func(t *testing.T) {
+		logFile := fakeKubectl(t, fakeKubectlVersion)
+		binary, err := kubectl.ResolveBinary(ctx, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if binary.Path != filepath.Join(filepath.Dir(logFile), "kubectl") {
+			t.Errorf("Unexpected path %q", binary.Path)
+		}
+		if binary.ClientVersion.GitVersion != "v1.30.2" {
+			t.Errorf("Unexpected version %+v", binary.ClientVersion)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		if _, err := kubectl.ResolveBinary(ctx, ""); err == nil || !strings.Contains(err.Error(), "not found on PATH") {
+			t.Errorf("Expected a not found error, got %v", err)
+		}
+		if _, err := kubectl.ResolveBinary(ctx, filepath.Join(t.TempDir(), "kubectl")); err == nil {
+			t.Error("Expected an error for a missing configured path")
+		}
+	})
+
+	t.Run("Not executable", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "kubectl")
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := kubectl.ResolveBinary(ctx, path); err == nil || !strings.Contains(err.Error(), "not an executable") {
+			t.Errorf("Expected a not executable error, got %v", err)
+		}
+	})
+
+	t.Run("Any file name", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "kubectl-1.30")
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+fakeKubectlVersion+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		binary, err := kubectl.ResolveBinary(ctx, path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if binary.Path != path || binary.ClientVersion.GitVersion != "v1.30.2" {
+			t.Errorf("Unexpected binary %+v", binary)
+		}
+	})
+}
+
+func TestPinBinary(t *testing.T) {
+	// A path with a space, under a name other than kubectl.
+	path := filepath.Join(t.TempDir(), "kube tools", "kubectl-1.30")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\n" + fakeKubectlVersion + "\necho \"pinned $*\"\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	binary, err := kubectl.ResolveBinary(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	kubectl.PinBinary(binary)
+	t.Cleanup(func() { kubectl.PinBinary(nil) })
+
+	// A kubectl put first on PATH later is not used.
+	otherLog := fakeKubectl(t, "echo other")
+	result, err := kubectl.RunKubectlCommand(context.Background(), "kubectl get pods", t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.TrimSpace(result.Stdout) != "pinned get pods" {
+		t.Errorf("Expected the pinned kubectl to run, got %q", result.Stdout)
+	}
+	if result.Metadata.ModifiesResource != "no" {
+		t.Errorf("Expected the command to be classified as given, got %q", result.Metadata.ModifiesResource)
+	}
+	if calls := invocations(t, otherLog); len(calls) != 0 {
+		t.Errorf("Expected the other kubectl not to run, got %q", calls)
+	}
+
+	kubectl.PinBinary(nil)
+	result, _ = kubectl.RunKubectlCommand(context.Background(), "kubectl get pods", t.TempDir(), "")
+	if strings.TrimSpace(result.Stdout) != "other" {
+		t.Errorf("Expected the kubectl on PATH to run once unpinned, got %q", result.Stdout)
+	}
+}
+
+func TestMinorVersionSkew(t *testing.T) {
+	client := &kubectl.VersionInfo{Major: "1", Minor: "30", GitVersion: "v1.30.2"}
+	tests := []struct {
+		server   *kubectl.VersionInfo
+		expected int
+		wantErr  bool
+	}{
+		{&kubectl.VersionInfo{Major: "1", Minor: "30"}, 0, false},
+		{&kubectl.VersionInfo{Major: "1", Minor: "28+"}, -2, false},
+		{&kubectl.VersionInfo{Major: "1", Minor: "31"}, 1, false},
+		{&kubectl.VersionInfo{Major: "2", Minor: "0"}, 0, true},
+		{&kubectl.VersionInfo{Major: "1", Minor: "x"}, 0, true},
+	}
+	for _, tt := range tests {
+		skew, err := kubectl.MinorVersionSkew(client, tt.server)
+		if (err != nil) != tt.wantErr || skew != tt.expected {
+			t.Errorf("MinorVersionSkew(%+v) = %d, %v; expected %d", tt.server, skew, err, tt.expected)
+		}
+	}
+}
+
+func TestKubectlTool_ReportsPinnedVersion(t *testing.T) {
+	fakeKubectl(t, "echo ok")
+	ctx := context.WithValue(toolContext(t), types.KubectlVersionKey, "v1.30.2")
+	result, err := (&kubectl.KubectlTool{}).Run(ctx, map[string]any{"command": "kubectl get pods"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if version := result.(*types.ExecResult).Metadata.KubectlVersion; version != "v1.30.2" {
+		t.Errorf("Expected the kubectl version in the metadata, got %q", version)
+	}
+}
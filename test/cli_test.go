@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"kubectl-go-mcp-server/internal/cli"
+	"kubectl-go-mcp-server/pkg/kubectl"
 )
 
 func TestBuildRootCommand(t *testing.T) {
@@ -97,6 +98,13 @@ func TestOptions_BindCLIFlags(t *testing.T) {
 	if opt.KubeConfigPath != "/test/path" {
 		t.Errorf("Expected KubeConfigPath '/test/path', got %q", opt.KubeConfigPath)
 	}
+
+	if err := flagSet.Parse([]string{"--config", "/test/config.json"}); err != nil {
+		t.Errorf("Flag parsing failed: %v", err)
+	}
+	if opt.ConfigPath != "/test/config.json" {
+		t.Errorf("Expected ConfigPath '/test/config.json', got %q", opt.ConfigPath)
+	}
 }
 
 func TestRunRootCommand(t *testing.T) {
@@ -104,6 +112,9 @@ func TestRunRootCommand(t *testing.T) {
 	if os.Getenv("HOME") == "" {
 		t.Setenv("HOME", "/tmp/test-home")
 	}
+	// StartMCPServer refuses to start without kubectl.
+	fakeKubectl(t, fakeKubectlVersion)
+	t.Cleanup(func() { kubectl.PinBinary(nil) })
 
 	t.Run("Default kubeconfig path", func(t *testing.T) {
 		opt := cli.Options{}
@@ -194,6 +205,14 @@ func TestStartMCPServer(t *testing.T) {
 	})
 }
 
+func TestStartMCPServer_WithoutKubectl(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	err := cli.StartMCPServer(context.Background(), cli.Options{})
+	if err == nil || !strings.Contains(err.Error(), "kubectl not found on PATH") {
+		t.Errorf("Expected StartMCPServer to refuse to start without kubectl, got %v", err)
+	}
+}
+
 func TestVersions(t *testing.T) {
 	// Test version command output
 	opt := &cli.Options{}
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/internal/mcp"
+)
+
+const completionKubectl = `case "$*" in
+  "config get-contexts -o name") printf 'prod\nstaging\n' ;;
+  "get namespaces -o name") printf 'namespace/default\nnamespace/shop\nnamespace/shipping\n' ;;
+  "get namespaces --context=prod -o name") printf 'namespace/prod-only\n' ;;
+  "get pods -n shop -o name") printf 'pod/web-1\npod/web-2\npod/worker-1\n' ;;
+  "get deployments.apps -n shop -o name") printf 'deployment.apps/web\n' ;;
+  api-resources*) echo "error: unreachable" >&2; exit 1 ;;
+  *) echo "unexpected: $*" >&2; exit 1 ;;
+esac`
+
+func complete(t *testing.T, server *mcp.Server, ref map[string]any, argument, value string, resolved map[string]string) map[string]any {
+	t.Helper()
+
+	params := map[string]any{
+		"ref":      ref,
+		"argument": map[string]any{"name": argument, "value": value},
+	}
+	if resolved != nil {
+		params["context"] = map[string]any{"arguments": resolved}
+	}
+	response := handleRequest(t, context.Background(), server, "completion/complete", params)
+	result, ok := response["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected a result, got %v", response)
+	}
+	return result["completion"].(map[string]any)
+}
+
+func completionValues(completion map[string]any) []string {
+	values := []string{}
+	for _, v := range completion["values"].([]any) {
+		values = append(values, v.(string))
+	}
+	return values
+}
+
+func TestCompletion(t *testing.T) {
+	logFile := fakeKubectl(t, completionKubectl)
+
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	objectTemplate := map[string]any{"type": "ref/resource", "uri": "k8s://{context}/{namespace}/{kind}/{name}"}
+	troubleshootPod := map[string]any{"type": "ref/prompt", "name": "troubleshoot-pod"}
+
+	tests := []struct {
+		name     string
+		ref      map[string]any
+		argument string
+		value    string
+		resolved map[string]string
+		want     []string
+	}{
+		{"Contexts", objectTemplate, "context", "", nil, []string{"_", "prod", "staging"}},
+		{"Namespaces by prefix", objectTemplate, "namespace", "sh", nil, []string{"shipping", "shop"}},
+		{"Namespaces in a context", objectTemplate, "namespace", "", map[string]string{"context": "prod"}, []string{"_", "prod-only"}},
+		{"Kinds fall back to built-in resources", objectTemplate, "kind", "deploy", nil, []string{"deployments"}},
+		{"Names within kind and namespace", objectTemplate, "name", "", map[string]string{"context": "_", "namespace": "shop", "kind": "deploy"}, []string{"web"}},
+		{"Names need a kind", objectTemplate, "name", "", map[string]string{"namespace": "shop"}, []string{}},
+		{"Prompt namespace", troubleshootPod, "namespace", "", nil, []string{"default", "shipping", "shop"}},
+		{"Prompt pod", troubleshootPod, "pod", "web", map[string]string{"namespace": "shop"}, []string{"web-1", "web-2"}},
+		{"Invalid resolved namespace", troubleshootPod, "pod", "", map[string]string{"namespace": "shop;ls"}, []string{}},
+		{"Unknown argument", troubleshootPod, "manifest", "", nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := completionValues(complete(t, server, tt.ref, tt.argument, tt.value, tt.resolved))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("Results are cached", func(t *testing.T) {
+		before := len(invocations(t, logFile))
+		for i := 0; i < 3; i++ {
+			complete(t, server, troubleshootPod, "pod", fmt.Sprintf("web-%d", i), map[string]string{"namespace": "shop"})
+		}
+		if calls := invocations(t, logFile)[before:]; len(calls) != 0 {
+			t.Errorf("Expected cached completions, got kubectl calls %v", calls)
+		}
+	})
+}
+
+func TestCompletionLimit(t *testing.T) {
+	fakeKubectl(t, `i=0; while [ $i -lt 150 ]; do echo "namespace/ns-$i"; i=$((i+1)); done`)
+
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	completion := complete(t, server, map[string]any{"type": "ref/prompt", "name": "capacity-report"}, "namespace", "ns-", nil)
+	values := completionValues(completion)
+	if len(values) != 100 || completion["total"] != float64(150) || completion["hasMore"] != true {
+		t.Errorf("Expected 100 of 150 values with hasMore, got %d values, total %v, hasMore %v", len(values), completion["total"], completion["hasMore"])
+	}
+	if !strings.HasPrefix(values[0], "ns-") {
+		t.Errorf("Unexpected value %q", values[0])
+	}
+}
@@ -37,6 +37,24 @@ func TestLoad(t *testing.T) {
 			configData: `{"name": "test-server"`,
 			wantErr:    true,
 		},
+		{
+			name:       "invalid strip field path",
+			configPath: "invalid-strip-fields.json",
+			configData: `{"output": {"normalize": {"enabled": true, "stripFields": ["metadata..uid"]}}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "usage threshold of zero",
+			configPath: "zero-threshold.json",
+			configData: `{"resourceUsage": {"thresholdPercent": 0}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "usage threshold over 100",
+			configPath: "high-threshold.json",
+			configData: `{"resourceUsage": {"thresholdPercent": 101}}`,
+			wantErr:    true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -83,6 +101,10 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.MCP.OperationTimeout <= 0 {
 		t.Error("Default config should have positive OperationTimeout")
 	}
+
+	if cfg.Scale.MinReplicas < 0 || cfg.Scale.MaxReplicas < cfg.Scale.MinReplicas {
+		t.Errorf("Default config should have consistent scale limits, got %+v", cfg.Scale)
+	}
 }
 
 func TestGetKubeconfigPath(t *testing.T) {
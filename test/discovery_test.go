@@ -0,0 +1,219 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+func apiResourcesOutput(rows ...[]string) string {
+	format := "%-14s %-12s %-22s %-11s %-13s %-62s %s\n"
+	var b strings.Builder
+	fmt.Fprintf(&b, format, "NAME", "SHORTNAMES", "APIVERSION", "NAMESPACED", "KIND", "VERBS", "CATEGORIES")
+	for _, row := range rows {
+		fmt.Fprintf(&b, format, row[0], row[1], row[2], row[3], row[4], row[5], row[6])
+	}
+	return b.String()
+}
+
+var sampleAPIResources = apiResourcesOutput(
+	[]string{"bindings", "", "v1", "true", "Binding", "create", ""},
+	[]string{"pods", "po", "v1", "true", "Pod", "create,delete,deletecollection,get,list,patch,update,watch", "all"},
+	[]string{"nodes", "no", "v1", "false", "Node", "create,delete,deletecollection,get,list,patch,update,watch", ""},
+	[]string{"tokenreviews", "", "authentication.k8s.io/v1", "false", "TokenReview", "create", ""},
+	[]string{"deployments", "deploy", "apps/v1", "true", "Deployment", "[create delete deletecollection get list patch update watch]", "all"},
+	[]string{"certificates", "cert,certs", "cert-manager.io/v1", "true", "Certificate", "[delete deletecollection get list patch create update watch]", "cert-manager"},
+)
+
+func TestParseAPIResources(t *testing.T) {
+	resources, err := kubectl.ParseAPIResources(sampleAPIResources)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resources) != 6 {
+		t.Fatalf("Expected 6 resources, got %d", len(resources))
+	}
+
+	bindings := resources[0]
+	if bindings.Name != "bindings" || len(bindings.ShortNames) != 0 || bindings.Kind != "Binding" {
+		t.Errorf("Unexpected bindings resource: %+v", bindings)
+	}
+
+	nodes := resources[2]
+	if nodes.Namespaced {
+		t.Error("nodes should be cluster scoped")
+	}
+
+	deployments := resources[4]
+	if deployments.Group() != "apps" {
+		t.Errorf("Expected group apps, got %q", deployments.Group())
+	}
+	if !deployments.SupportsVerb("list") || len(deployments.Verbs) != 8 {
+		t.Errorf("Expected bracketed verbs to be parsed, got %v", deployments.Verbs)
+	}
+	if len(deployments.Categories) != 1 || deployments.Categories[0] != "all" {
+		t.Errorf("Expected category all, got %v", deployments.Categories)
+	}
+
+	certificates := resources[5]
+	if len(certificates.ShortNames) != 2 || certificates.ShortNames[1] != "certs" {
+		t.Errorf("Expected short names cert,certs, got %v", certificates.ShortNames)
+	}
+
+	t.Run("Missing header", func(t *testing.T) {
+		if _, err := kubectl.ParseAPIResources("error: the server doesn't have a resource type"); err == nil {
+			t.Error("Expected error for output without header")
+		}
+	})
+}
+
+func TestDiscoveryCache_Resolve(t *testing.T) {
+	resources, err := kubectl.ParseAPIResources(sampleAPIResources)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cache := kubectl.NewDiscoveryCache(0)
+	cache.Update(resources)
+
+	tests := []struct {
+		alias    string
+		expected string
+	}{
+		{"po", "pods"},
+		{"Pod", "pods"},
+		{"deploy", "deployments"},
+		{"deployment", "deployments"},
+		{"deployments.apps", "deployments"},
+		{"certs", "certificates"},
+		{"certificates.cert-manager.io", "certificates"},
+		// Falls back to the built-in aliases
+		{"svc", "services"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			resource, ok := cache.Resolve(tt.alias)
+			if !ok {
+				t.Fatalf("Expected %q to resolve", tt.alias)
+			}
+			if resource.Name != tt.expected {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.alias, resource.Name, tt.expected)
+			}
+		})
+	}
+
+	if _, ok := cache.Resolve("widgets"); ok {
+		t.Error("Unknown resource type should not resolve")
+	}
+
+	t.Run("Reset drops the discovered resources", func(t *testing.T) {
+		cache := kubectl.NewDiscoveryCache(0)
+		cache.Update(resources)
+		cache.Reset()
+		if _, ok := cache.Resolve("certs"); ok {
+			t.Error("Expected a resource of the previous context not to resolve")
+		}
+		if resource, ok := cache.Resolve("deploy"); !ok || resource.Kind != "Deployment" {
+			t.Errorf("Expected deploy to resolve to Deployment from the built-in aliases, got %+v", resource)
+		}
+		if !cache.Stale() {
+			t.Error("Expected a reset cache to be refreshed on next use")
+		}
+	})
+
+	t.Run("Empty cache uses built-in aliases", func(t *testing.T) {
+		resource, ok := kubectl.NewDiscoveryCache(0).Resolve("deploy")
+		if !ok || resource.Kind != "Deployment" {
+			t.Errorf("Expected deploy to resolve to Deployment, got %+v", resource)
+		}
+	})
+}
+
+func TestDiscoveryCache_CheckCommand(t *testing.T) {
+	resources, err := kubectl.ParseAPIResources(sampleAPIResources)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cache := kubectl.NewDiscoveryCache(0)
+	cache.Update(resources)
+
+	tests := []struct {
+		command string
+		wantErr bool
+	}{
+		{"kubectl get po", false},
+		{"kubectl get -n kube-system deploy/coredns", false},
+		{"kubectl delete certs my-cert", false},
+		{"kubectl get tokenreviews", true},
+		{"kubectl delete bindings foo", true},
+		{"kubectl get widgets", false},
+		{"kubectl logs my-pod", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			err := cache.CheckCommand(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommandResourceArg(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{"kubectl get pods", "pods"},
+		{"kubectl get -n default -o wide deploy/app", "deploy"},
+		{"kubectl get --namespace=default svc,pods", "svc"},
+		{"kubectl get", ""},
+		{"kubectl exec my-pod -- ps", "my-pod"},
+		{"ls -la /tmp", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := kubectl.CommandResourceArg(tt.command); got != tt.expected {
+				t.Errorf("CommandResourceArg(%q) = %q, want %q", tt.command, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAPIResourcesTool(t *testing.T) {
+	tool := &kubectl.APIResourcesTool{Discovery: kubectl.NewDiscoveryCache(0)}
+
+	if tool.Name() != "api_resources" {
+		t.Errorf("Expected name api_resources, got %q", tool.Name())
+	}
+	if tool.CheckModifiesResource(map[string]any{}) != "no" {
+		t.Error("api_resources should not modify resources")
+	}
+
+	ctx := context.WithValue(context.Background(), types.KubeconfigKey, "")
+	ctx = context.WithValue(ctx, types.WorkdirKey, t.TempDir())
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{"scope": "global"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if res := result.(*kubectl.APIResourcesResult); !strings.Contains(res.Error, "scope") {
+			t.Errorf("Expected scope error, got %q", res.Error)
+		}
+	})
+
+	t.Run("Resolve without cluster", func(t *testing.T) {
+		result, err := tool.Run(ctx, map[string]any{"name": "po"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res := result.(*kubectl.APIResourcesResult)
+		if res.Count != 1 || res.Resources[0].Kind != "Pod" {
+			t.Errorf("Expected po to resolve to Pod, got %+v", res)
+		}
+	})
+}
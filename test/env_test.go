@@ -0,0 +1,76 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+)
+
+func TestChildEnv(t *testing.T) {
+	t.Cleanup(func() { kubectl.SetEnv(nil, false) })
+	t.Setenv("AWS_PROFILE", "dev")
+	t.Setenv("GITHUB_TOKEN", "ghp_secret")
+	t.Setenv("KUBECTL_EXTERNAL_DIFF", "meld")
+	t.Setenv("HTTPS_PROXY", "http://proxy:3128")
+	t.Setenv("KUBECONFIG", "/elsewhere/config")
+
+	has := func(env []string, name string) bool {
+		for _, envVar := range env {
+			if strings.HasPrefix(envVar, name+"=") {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("Default allowlist", func(t *testing.T) {
+		kubectl.SetEnv(nil, false)
+		env := kubectl.ChildEnv()
+		for _, name := range []string{"PATH", "AWS_PROFILE"} {
+			if !has(env, name) {
+				t.Errorf("Expected %s to be passed, got %q", name, env)
+			}
+		}
+		for _, name := range []string{"GITHUB_TOKEN", "KUBECTL_EXTERNAL_DIFF", "HTTPS_PROXY", "KUBECONFIG"} {
+			if has(env, name) {
+				t.Errorf("Expected %s not to be passed", name)
+			}
+		}
+	})
+
+	t.Run("Configured allowlist and proxy", func(t *testing.T) {
+		kubectl.SetEnv([]string{"PATH", "KUBECTL_*", "KUBECONFIG"}, true)
+		env := kubectl.ChildEnv()
+		for _, name := range []string{"PATH", "KUBECTL_EXTERNAL_DIFF", "HTTPS_PROXY"} {
+			if !has(env, name) {
+				t.Errorf("Expected %s to be passed, got %q", name, env)
+			}
+		}
+		for _, name := range []string{"AWS_PROFILE", "HOME", "KUBECONFIG"} {
+			if has(env, name) {
+				t.Errorf("Expected %s not to be passed", name)
+			}
+		}
+	})
+
+	t.Run("Commands run with it", func(t *testing.T) {
+		kubectl.SetEnv(nil, false)
+		fakeKubectl(t, `echo "profile=$AWS_PROFILE token=$GITHUB_TOKEN kubeconfig=$KUBECONFIG"`)
+		result, err := kubectl.RunKubectlCommand(context.Background(), "kubectl get pods", t.TempDir(), "/tmp/kubeconfig")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.TrimSpace(result.Stdout) != "profile=dev token= kubeconfig=/tmp/kubeconfig" {
+			t.Errorf("Unexpected environment: %q", result.Stdout)
+		}
+	})
+}
+
+func TestDescribeEnv(t *testing.T) {
+	described := kubectl.DescribeEnv([]string{"PATH=/usr/bin", "AWS_SECRET_ACCESS_KEY=abc", "AZURE_CLIENT_ID=id"})
+	if described != "PATH=/usr/bin AWS_SECRET_ACCESS_KEY=*** AZURE_CLIENT_ID=id" {
+		t.Errorf("Unexpected description %q", described)
+	}
+}
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"kubectl-go-mcp-server/internal/mcp"
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+const metadataKubeconfig = `apiVersion: v1
+kind: Config
+current-context: staging
+contexts:
+- name: staging
+  context:
+    cluster: staging
+    namespace: shop
+- name: prod
+  context:
+    cluster: prod
+`
+
+func TestKubectlTool_Metadata(t *testing.T) {
+	fakeKubectl(t, `echo "ran $*"`)
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(metadataKubeconfig), 0o600); err != nil {
+		t.Fatalf("Failed to write kubeconfig: %v", err)
+	}
+	ctx := context.WithValue(toolContext(t), types.KubeconfigKey, kubeconfig)
+	ctx = context.WithValue(ctx, types.KubectlVersionKey, "v1.30.2")
+
+	run := func(t *testing.T, command string) *types.ExecMetadata {
+		t.Helper()
+		result, err := (&kubectl.KubectlTool{}).Run(ctx, map[string]any{"command": command})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		metadata := result.(*types.ExecResult).Metadata
+		if metadata == nil {
+			t.Fatalf("Expected metadata, got %+v", result)
+		}
+		return metadata
+	}
+
+	t.Run("Timing and provenance", func(t *testing.T) {
+		before := time.Now()
+		metadata := run(t, "kubectl get pods")
+		if metadata.StartTime.Before(before.Add(-time.Second)) || metadata.StartTime.After(time.Now()) {
+			t.Errorf("Unexpected start time %v", metadata.StartTime)
+		}
+		if metadata.DurationMs < 0 {
+			t.Errorf("Unexpected duration %d", metadata.DurationMs)
+		}
+		if metadata.KubectlVersion != "v1.30.2" || metadata.ModifiesResource != "no" {
+			t.Errorf("Unexpected metadata %+v", metadata)
+		}
+		if n := len(metadata.Argv); n < 3 || metadata.Argv[n-2] != "-c" || metadata.Argv[n-1] != "kubectl get pods" {
+			t.Errorf("Unexpected argv %q", metadata.Argv)
+		}
+		if run(t, "kubectl delete pod web-1").ModifiesResource != "yes" {
+			t.Error("Expected delete to be classified as modifying")
+		}
+	})
+
+	t.Run("Context and namespace", func(t *testing.T) {
+		tests := []struct {
+			command       string
+			context       string
+			namespace     string
+			allNamespaces bool
+		}{
+			{"kubectl get pods", "staging", "shop", false},
+			{"kubectl get pods -n billing", "staging", "billing", false},
+			{"kubectl get pods --context prod", "prod", "default", false},
+			{"kubectl get pods --context=prod -A", "prod", "", true},
+		}
+		for _, tt := range tests {
+			metadata := run(t, tt.command)
+			if metadata.Context != tt.context || metadata.Namespace != tt.namespace || metadata.AllNamespaces != tt.allNamespaces {
+				t.Errorf("%q: expected %s/%s (all %v), got %s/%s (all %v)", tt.command,
+					tt.context, tt.namespace, tt.allNamespaces, metadata.Context, metadata.Namespace, metadata.AllNamespaces)
+			}
+		}
+	})
+
+	t.Run("Secrets are masked", func(t *testing.T) {
+		result, err := (&kubectl.KubectlTool{}).Run(ctx, map[string]any{
+			"command": "kubectl create secret generic db --from-literal=password=hunter2 --namespace shop",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		exec := result.(*types.ExecResult)
+		argv := strings.Join(exec.Metadata.Argv, " ")
+		if strings.Contains(argv, "hunter2") || strings.Contains(exec.Command, "hunter2") {
+			t.Errorf("Expected the secret to be masked, got %q and %q", argv, exec.Command)
+		}
+		if !strings.Contains(argv, "--from-literal=password=***") {
+			t.Errorf("Expected the key to be kept, got %q", argv)
+		}
+	})
+}
+
+func TestMaskSecrets(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected string
+	}{
+		{"kubectl get pods --token abc123", "kubectl get pods --token ***"},
+		{"kubectl get pods --token=abc123 -n shop", "kubectl get pods --token=*** -n shop"},
+		{"kubectl get pods --password 's3cret word'", "kubectl get pods --password ***"},
+		{"kubectl create secret docker-registry reg --docker-password=p4ss", "kubectl create secret docker-registry reg --docker-password=***"},
+		{"kubectl create secret generic db --from-literal=user=admin --from-literal pass=x", "kubectl create secret generic db --from-literal=user=*** --from-literal pass=***"},
+		{"kubectl create secret generic db --from-literal='pass=a b'", "kubectl create secret generic db --from-literal='pass=***'"},
+		{"kubectl get pods --token-file /run/token", "kubectl get pods --token-file /run/token"},
+	}
+	for _, tt := range tests {
+		if got := kubectl.MaskSecrets(tt.command); got != tt.expected {
+			t.Errorf("MaskSecrets(%q) = %q, expected %q", tt.command, got, tt.expected)
+		}
+	}
+}
+
+func TestExecResultMetadataSchema(t *testing.T) {
+	result := &types.ExecResult{Metadata: &types.ExecMetadata{
+		StartTime:        time.Now(),
+		Argv:             []string{"bash", "-c", "kubectl get pods"},
+		ModifiesResource: "no",
+	}}
+	structured, err := mcp.ToolResultToMap(result)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := types.SchemaFor(&types.ExecResult{}).Validate(structured); err != nil {
+		t.Errorf("Expected metadata to match the output schema: %v", err)
+	}
+	metadata := structured["metadata"].(map[string]any)
+	if !reflect.DeepEqual(metadata["argv"], []any{"bash", "-c", "kubectl get pods"}) {
+		t.Errorf("Unexpected argv %v", metadata["argv"])
+	}
+}
@@ -0,0 +1,189 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+// fakeKubectl puts a shell script named kubectl first on PATH for the rest of
+// the test. Every invocation's arguments are appended to the returned log file.
+func fakeKubectl(t *testing.T, body string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "invocations.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + logFile + "\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake kubectl: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logFile
+}
+
+func invocations(t *testing.T, logFile string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		t.Fatalf("Failed to read invocation log: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func toolContext(t *testing.T) context.Context {
+	ctx := context.WithValue(context.Background(), types.KubeconfigKey, "")
+	return context.WithValue(ctx, types.WorkdirKey, t.TempDir())
+}
+
+func TestParseKubectlVersion(t *testing.T) {
+	output := `{
+  "clientVersion": {"major": "1", "minor": "30", "gitVersion": "v1.30.1"},
+  "serverVersion": {"major": "1", "minor": "28", "gitVersion": "v1.28.4"}
+}
+WARNING: version difference between client (1.30) and server (1.28) exceeds the supported minor version skew of +/-1
+`
+	version, err := kubectl.ParseKubectlVersion(output)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if version.ClientVersion.GitVersion != "v1.30.1" || version.ServerVersion.Minor != "28" {
+		t.Errorf("Unexpected version: %+v %+v", version.ClientVersion, version.ServerVersion)
+	}
+
+	if _, err := kubectl.ParseKubectlVersion("error: not found"); err == nil {
+		t.Error("Expected error for non-JSON output")
+	}
+}
+
+func TestExplainTool(t *testing.T) {
+	tool := &kubectl.ExplainTool{}
+
+	if tool.CheckModifiesResource(map[string]any{}) != "no" {
+		t.Error("explain should not modify resources")
+	}
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		tests := []struct {
+			name string
+			args map[string]any
+			want string
+		}{
+			{"Missing kind", map[string]any{}, "kind is required"},
+			{"Injected kind", map[string]any{"kind": "pods;ls"}, "invalid kind"},
+			{"Bad field path", map[string]any{"kind": "pod", "field": "spec..containers"}, "invalid field path"},
+			{"Bad api version", map[string]any{"kind": "hpa", "api_version": "v2 --foo"}, "invalid api_version"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				result, err := tool.Run(toolContext(t), tt.args)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if res := result.(*kubectl.ExplainResult); !strings.Contains(res.Error, tt.want) {
+					t.Errorf("Expected error containing %q, got %q", tt.want, res.Error)
+				}
+			})
+		}
+	})
+
+	t.Run("Results are cached per cluster version", func(t *testing.T) {
+		logFile := fakeKubectl(t, `case "$1" in
+  version) echo '{"serverVersion": {"gitVersion": "v1.30.0"}}' ;;
+  explain) echo "KIND: Deployment"; echo "FIELD: replicas <integer>" ;;
+esac`)
+		tool := &kubectl.ExplainTool{}
+		ctx := toolContext(t)
+		args := map[string]any{"kind": "deploy", "field": ".spec.replicas"}
+
+		first, err := tool.Run(ctx, args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res := first.(*kubectl.ExplainResult)
+		if res.Error != "" || res.Cached || !strings.Contains(res.Documentation, "replicas <integer>") {
+			t.Fatalf("Unexpected first result: %+v", res)
+		}
+		if res.Resource != "deployments" || res.ServerVersion != "v1.30.0" {
+			t.Errorf("Unexpected resource/version: %q %q", res.Resource, res.ServerVersion)
+		}
+
+		second, err := tool.Run(ctx, args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if res := second.(*kubectl.ExplainResult); !res.Cached {
+			t.Errorf("Expected second lookup to be served from cache: %+v", res)
+		}
+
+		var explains []string
+		for _, call := range invocations(t, logFile) {
+			if strings.HasPrefix(call, "explain") {
+				explains = append(explains, call)
+			}
+		}
+		if len(explains) != 1 || explains[0] != "explain deploy.spec.replicas" {
+			t.Errorf("Expected a single kubectl explain call, got %v", explains)
+		}
+	})
+
+	t.Run("Results are cached per context", func(t *testing.T) {
+		fakeKubectl(t, `context=$(sed -n 's/^current-context: //p' "$KUBECONFIG")
+case "$1" in
+  version) echo '{"serverVersion": {"gitVersion": "v1.30.0"}}' ;;
+  explain) echo "FIELD: replicas from $context" ;;
+esac`)
+		kubeconfig := filepath.Join(t.TempDir(), "config")
+		useContext := func(name string) {
+			t.Helper()
+			data := strings.Replace(metadataKubeconfig, "current-context: staging", "current-context: "+name, 1)
+			if err := os.WriteFile(kubeconfig, []byte(data), 0o600); err != nil {
+				t.Fatalf("Failed to write kubeconfig: %v", err)
+			}
+		}
+		tool := &kubectl.ExplainTool{}
+		ctx := context.WithValue(toolContext(t), types.KubeconfigKey, kubeconfig)
+		args := map[string]any{"kind": "deploy", "field": "spec.replicas"}
+		run := func() *kubectl.ExplainResult {
+			t.Helper()
+			result, err := tool.Run(ctx, args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			return result.(*kubectl.ExplainResult)
+		}
+
+		useContext("staging")
+		if res := run(); res.Cached || !strings.Contains(res.Documentation, "from staging") {
+			t.Fatalf("Unexpected first result: %+v", res)
+		}
+		useContext("prod")
+		if res := run(); res.Cached || !strings.Contains(res.Documentation, "from prod") {
+			t.Errorf("Expected a context switch to miss the cache, got %+v", res)
+		}
+		useContext("staging")
+		if res := run(); !res.Cached || !strings.Contains(res.Documentation, "from staging") {
+			t.Errorf("Expected the staging result from the cache, got %+v", res)
+		}
+	})
+
+	t.Run("Group qualified kinds use api-version", func(t *testing.T) {
+		logFile := fakeKubectl(t, `echo "ok"`)
+		tool := &kubectl.ExplainTool{}
+		if _, err := tool.Run(toolContext(t), map[string]any{"kind": "deployments.apps", "recursive": true}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		calls := invocations(t, logFile)
+		if last := calls[len(calls)-1]; last != "explain deployments --recursive --api-version=apps/v1" {
+			t.Errorf("Unexpected explain invocation: %q", last)
+		}
+	})
+}
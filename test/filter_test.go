@@ -0,0 +1,251 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+const podListJSON = `{
+  "kind": "List",
+  "items": [
+    {
+      "metadata": {"name": "web-1", "labels": {"app.kubernetes.io/name": "web"}},
+      "spec": {"nodeName": "node-1", "containers": [{"image": "nginx:1.25"}, {"image": "envoy:1.29"}]},
+      "status": {"phase": "Running", "restartCount": 0}
+    },
+    {
+      "metadata": {"name": "web-2", "labels": {"app.kubernetes.io/name": "web"}},
+      "spec": {"nodeName": "node-2", "containers": [{"image": "nginx:1.25"}]},
+      "status": {"phase": "Pending", "restartCount": 3}
+    },
+    {
+      "metadata": {"name": "db-0"},
+      "spec": {"nodeName": "node-1", "containers": [{"image": "postgres:16"}]},
+      "status": {"phase": "Running", "restartCount": 12}
+    }
+  ]
+}`
+
+func compactJSON(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+// TestFilter checks filters against jq's behaviour: each value a filter
+// produces is printed on its own, so a list needs [...].
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{"Field", ".kind", `"List"`},
+		{"Index", ".items[0].metadata.name", `"web-1"`},
+		{"Negative index", ".items[-1].metadata.name", `"db-0"`},
+		{"Missing field", ".items[0].metadata.namespace", `null`},
+		{"Iteration", ".items[].metadata.name", `"web-1" "web-2" "db-0"`},
+		{"Collected list", "[.items[].metadata.name]", `["web-1","web-2","db-0"]`},
+		{"Slice", ".items[1:][].metadata.name", `"web-2" "db-0"`},
+		{"Recursive descent", "[.. | .image? // empty]", `["nginx:1.25","envoy:1.29","nginx:1.25","postgres:16"]`},
+		{"Quoted key", `.items[0].metadata.labels["app.kubernetes.io/name"]`, `"web"`},
+		{"Select", `.items[] | select(.status.phase != "Running") | .metadata.name`, `"web-2"`},
+		{"Numeric comparison", `.items[] | select(.status.restartCount > 2 and .spec.nodeName == "node-1") | .metadata.name`, `"db-0"`},
+		{"Regexp", `.items[] | select(.metadata.name | test("^web-")) | .metadata.name`, `"web-1" "web-2"`},
+		{"Any of iterated values", `.items[] | select(any(.spec.containers[]; .image == "envoy:1.29")) | .metadata.name`, `"web-1"`},
+		{"Negation", `.items[] | select(.metadata.labels | not) | .metadata.name`, `"db-0"`},
+		{"Object construction", `.items[] | select(.status.phase == "Pending") | {name: .metadata.name, images: [.spec.containers[].image]}`,
+			`{"images":["nginx:1.25"],"name":"web-2"}`},
+		{"Arithmetic", "[.items[].status.restartCount] | add", `15`},
+		{"Length", ".items | length", `3`},
+		{"Keys", ".items[0].status | keys", `["phase","restartCount"]`},
+		{"No matches", `.items[] | select(.status.phase == "Failed")`, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := kubectl.ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("Unexpected parse error: %v", err)
+			}
+			out, err := filter.Apply([]byte(podListJSON))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := compactJSON(string(out)); got != compactJSON(tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("Large integers keep their precision", func(t *testing.T) {
+		filter, err := kubectl.ParseFilter(".big, .small + 1")
+		if err != nil {
+			t.Fatalf("Unexpected parse error: %v", err)
+		}
+		out, err := filter.Apply([]byte(`{"big": 12345678901234567890, "small": 1.5}`))
+		if err != nil || string(out) != "12345678901234567890\n2.5\n" {
+			t.Errorf("Unexpected output %q, error %v", out, err)
+		}
+	})
+
+	t.Run("Evaluation errors", func(t *testing.T) {
+		filter, err := kubectl.ParseFilter(`.items[] | select(.metadata.name | test("("))`)
+		if err != nil {
+			t.Fatalf("Unexpected parse error: %v", err)
+		}
+		if _, err := filter.Apply([]byte(podListJSON)); err == nil || !strings.Contains(err.Error(), "filter failed") {
+			t.Errorf("Expected an evaluation error, got %v", err)
+		}
+	})
+
+	t.Run("No environment", func(t *testing.T) {
+		t.Setenv("FILTER_SECRET", "hunter2")
+		filter, err := kubectl.ParseFilter("$ENV.FILTER_SECRET, env.FILTER_SECRET")
+		if err != nil {
+			t.Fatalf("Unexpected parse error: %v", err)
+		}
+		if out, err := filter.Apply([]byte(podListJSON)); err != nil || strings.Contains(string(out), "hunter2") {
+			t.Errorf("Expected no environment, got %q, error %v", out, err)
+		}
+	})
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"items",
+		".items[",
+		"{name .metadata.name}",
+		`.metadata.labels["app`,
+		".items[0] extra",
+		"input",
+	} {
+		if _, err := kubectl.ParseFilter(expr); err == nil || !strings.Contains(err.Error(), "filter") {
+			t.Errorf("Expected %q to be rejected, got %v", expr, err)
+		}
+	}
+
+	for _, expr := range []string{"{.items[*].metadata.name}", "$.items[*].metadata.name", `.items[?(@.status.phase == "Running")]`} {
+		if _, err := kubectl.ParseFilter(expr); err == nil || !strings.Contains(err.Error(), "JSONPath is not supported") {
+			t.Errorf("Expected %q to be rejected as JSONPath, got %v", expr, err)
+		}
+	}
+}
+
+func TestKubectlTool_Filter(t *testing.T) {
+	logFile := fakeKubectl(t, "cat <<'EOF'\n"+podListJSON+"\nEOF\necho 'Warning: deprecated' >&2")
+	tool := &kubectl.KubectlTool{}
+
+	run := func(t *testing.T, command, filter string) *types.ExecResult {
+		t.Helper()
+		result, err := tool.Run(toolContext(t), map[string]any{"command": command, "filter": filter})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result.(*types.ExecResult)
+	}
+
+	t.Run("Runs with -o json and filters stdout", func(t *testing.T) {
+		result := run(t, "kubectl get pods -n shop", ".items[].metadata.name")
+		if result.Error != "" {
+			t.Fatalf("Unexpected error: %s", result.Error)
+		}
+		expected := "\"web-1\"\n\"web-2\"\n\"db-0\"\nWarning: deprecated\n"
+		if result.Stdout != expected {
+			t.Errorf("Expected filtered output followed by stderr, got:\n%s", result.Stdout)
+		}
+		calls := invocations(t, logFile)
+		if last := calls[len(calls)-1]; last != "get pods -n shop -o json" {
+			t.Errorf("Expected -o json to be added, got %q", last)
+		}
+	})
+
+	t.Run("Keeps an explicit -o json", func(t *testing.T) {
+		result := run(t, "kubectl get pods --output=json", ".kind")
+		if result.Error != "" || !strings.HasPrefix(result.Stdout, `"List"`) {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+		calls := invocations(t, logFile)
+		if last := calls[len(calls)-1]; last != "get pods --output=json" {
+			t.Errorf("Expected the command unchanged, got %q", last)
+		}
+	})
+
+	t.Run("Rejects other output formats", func(t *testing.T) {
+		result := run(t, "kubectl get pods -o yaml", ".kind")
+		if !strings.Contains(result.Error, "filter needs JSON output") {
+			t.Errorf("Expected an output format error, got %+v", result)
+		}
+	})
+
+	t.Run("Rejects commands without JSON output", func(t *testing.T) {
+		before := len(invocations(t, logFile))
+		for _, command := range []string{
+			"kubectl exec web-1 -- cat /etc/config.json",
+			"kubectl logs web-1",
+			"kubectl top pods",
+			"kubectl describe pod web-1",
+			"kubectl config get-contexts",
+		} {
+			if result := run(t, command, ".kind"); !strings.Contains(result.Error, "filter needs a command that prints JSON") {
+				t.Errorf("%q: expected a command error, got %+v", command, result)
+			}
+		}
+		if len(invocations(t, logFile)) != before {
+			t.Error("Expected kubectl not to run")
+		}
+	})
+
+	t.Run("Rejects invalid filters before running", func(t *testing.T) {
+		before := len(invocations(t, logFile))
+		result := run(t, "kubectl get pods", ".items[")
+		if !strings.Contains(result.Error, "invalid filter") {
+			t.Errorf("Expected a filter error, got %+v", result)
+		}
+		if len(invocations(t, logFile)) != before {
+			t.Error("Expected kubectl not to run")
+		}
+	})
+}
+
+func TestKubectlTool_FilterNonJSON(t *testing.T) {
+	fakeKubectl(t, `echo "not json"`)
+
+	result, err := (&kubectl.KubectlTool{}).Run(toolContext(t), map[string]any{"command": "kubectl get pods", "filter": ".kind"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	execResult := result.(*types.ExecResult)
+	if !strings.Contains(execResult.Error, "output is not JSON") || execResult.Stdout != "not json\n" {
+		t.Errorf("Expected the raw output with a filter error, got %+v", execResult)
+	}
+}
+
+func TestKubectlTool_FilterOverProcessLimit(t *testing.T) {
+	fakeKubectl(t, "cat <<'EOF'\n"+podListJSON+"\nEOF")
+
+	t.Run("Configured limit", func(t *testing.T) {
+		tool := &kubectl.KubectlTool{MaxProcessBytes: 64}
+		result, err := tool.Run(toolContext(t), map[string]any{"command": "kubectl get pods", "filter": ".kind"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		execResult := result.(*types.ExecResult)
+		if !strings.Contains(execResult.Error, "output exceeds 64 bytes") || len(execResult.Stdout) != 64 {
+			t.Errorf("Expected the first 64 bytes with a limit error, got %+v", execResult)
+		}
+	})
+
+	t.Run("Defaults to a multiple of the output limit", func(t *testing.T) {
+		tool := &kubectl.KubectlTool{OutputLimits: types.OutputLimits{MaxBytes: 10}}
+		result, err := tool.Run(toolContext(t), map[string]any{"command": "kubectl get pods", "filter": ".kind"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if execResult := result.(*types.ExecResult); !strings.Contains(execResult.Error, "output exceeds 100 bytes") {
+			t.Errorf("Expected a limit error, got %+v", execResult)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+const podLogs = `starting server
+INFO listening on :8080
+ERROR connection refused
+retrying in 1s
+INFO connected
+INFO request served
+WARN slow request
+ERROR timeout
+shutting down`
+
+func TestLineGrep(t *testing.T) {
+	tests := []struct {
+		name     string
+		grep     kubectl.LineGrep
+		expected string
+	}{
+		{
+			name:     "Matching lines",
+			grep:     kubectl.LineGrep{Pattern: regexp.MustCompile("ERROR")},
+			expected: "ERROR connection refused\nERROR timeout\n",
+		},
+		{
+			name:     "Inverted",
+			grep:     kubectl.LineGrep{Pattern: regexp.MustCompile("^(INFO|ERROR)"), Invert: true},
+			expected: "starting server\nretrying in 1s\nWARN slow request\nshutting down\n",
+		},
+		{
+			name:     "Context groups are separated",
+			grep:     kubectl.LineGrep{Pattern: regexp.MustCompile("ERROR"), Context: 1},
+			expected: "INFO listening on :8080\nERROR connection refused\nretrying in 1s\n--\nWARN slow request\nERROR timeout\nshutting down\n",
+		},
+		{
+			name:     "Overlapping context is merged",
+			grep:     kubectl.LineGrep{Pattern: regexp.MustCompile("ERROR"), Context: 2},
+			expected: "starting server\nINFO listening on :8080\nERROR connection refused\nretrying in 1s\nINFO connected\nINFO request served\nWARN slow request\nERROR timeout\nshutting down\n",
+		},
+		{
+			name:     "Max matches keeps trailing context",
+			grep:     kubectl.LineGrep{Pattern: regexp.MustCompile("INFO"), Context: 1, MaxMatches: 2},
+			expected: "starting server\nINFO listening on :8080\nERROR connection refused\nretrying in 1s\nINFO connected\nINFO request served\n",
+		},
+		{
+			name:     "Case-insensitive",
+			grep:     kubectl.LineGrep{Pattern: regexp.MustCompile("(?i)warn|shutting")},
+			expected: "WARN slow request\nshutting down\n",
+		},
+		{
+			name:     "No matches",
+			grep:     kubectl.LineGrep{Pattern: regexp.MustCompile("FATAL")},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.grep.Apply([]byte(podLogs))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestKubectlTool_Grep(t *testing.T) {
+	fakeKubectl(t, "cat <<'EOF'\n"+podLogs+"\nEOF")
+	tool := &kubectl.KubectlTool{}
+
+	run := func(t *testing.T, args map[string]any) *types.ExecResult {
+		t.Helper()
+		result, err := tool.Run(toolContext(t), args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result.(*types.ExecResult)
+	}
+
+	t.Run("Searches logs without a pipe", func(t *testing.T) {
+		result := run(t, map[string]any{"command": "kubectl logs web-1", "grep": "ERROR", "max_matches": float64(1)})
+		if result.Error != "" || result.Stdout != "ERROR connection refused\n" {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("Applies after the filter", func(t *testing.T) {
+		fakeKubectl(t, "cat <<'EOF'\n"+podListJSON+"\nEOF")
+		result := run(t, map[string]any{"command": "kubectl get pods", "filter": ".items[].metadata.name", "grep": "web"})
+		if result.Error != "" || result.Stdout != "\"web-1\"\n\"web-2\"\n" {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("Streams long output", func(t *testing.T) {
+		fakeKubectl(t, `seq 1 200000; echo 'ERROR last'; echo 'trailing' >&2`)
+		result := run(t, map[string]any{"command": "kubectl logs web-1", "grep": "ERROR|^199999$", "context_lines": float64(1)})
+		if result.Error != "" || result.Stdout != "199998\n199999\n200000\nERROR last\ntrailing\n" {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	})
+
+	for _, tt := range []struct {
+		name  string
+		args  map[string]any
+		error string
+	}{
+		{"Invalid pattern", map[string]any{"grep": "ERROR("}, "invalid grep pattern"},
+		{"Options without pattern", map[string]any{"context_lines": float64(2)}, "need a grep pattern"},
+		{"Too much context", map[string]any{"grep": "x", "context_lines": float64(500)}, "context_lines must be between"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.args["command"] = "kubectl logs web-1"
+			if result := run(t, tt.args); !strings.Contains(result.Error, tt.error) {
+				t.Errorf("Expected error containing %q, got %+v", tt.error, result)
+			}
+		})
+	}
+}
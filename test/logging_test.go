@@ -0,0 +1,115 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"kubectl-go-mcp-server/internal/mcp"
+)
+
+// loggingSession is a fakeSession that accepts logging/setLevel.
+type loggingSession struct {
+	*fakeSession
+
+	mu    sync.Mutex
+	level mcpgo.LoggingLevel
+}
+
+func (s *loggingSession) SetLogLevel(level mcpgo.LoggingLevel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.level = level
+}
+
+func (s *loggingSession) GetLogLevel() mcpgo.LoggingLevel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.level == "" {
+		return mcpgo.LoggingLevelError
+	}
+	return s.level
+}
+
+func drainNotifications(session *fakeSession) []mcpgo.JSONRPCNotification {
+	var notifications []mcpgo.JSONRPCNotification
+	for {
+		select {
+		case n := <-session.notifications:
+			notifications = append(notifications, n)
+		default:
+			return notifications
+		}
+	}
+}
+
+func TestClientLogging(t *testing.T) {
+	fakeKubectl(t, `echo 'pod/web-1 Running'`)
+
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	session := &loggingSession{fakeSession: newFakeSession("session-1")}
+	ctx := server.GetMCPServer().WithContext(context.Background(), session)
+	if err := server.GetMCPServer().RegisterSession(ctx, session); err != nil {
+		t.Fatalf("Failed to register session: %v", err)
+	}
+
+	callKubectl := func(command string) {
+		t.Helper()
+		response := handleRequest(t, ctx, server, "tools/call", map[string]any{
+			"name":      "kubectl",
+			"arguments": map[string]any{"command": command},
+		})
+		if response["error"] != nil {
+			t.Fatalf("Unexpected tools/call error: %v", response["error"])
+		}
+	}
+
+	// Without logging/setLevel only errors are sent.
+	callKubectl("kubectl get pods; rm -rf /")
+	if notifications := drainNotifications(session.fakeSession); len(notifications) != 0 {
+		t.Errorf("Expected no log messages at the default level, got %+v", notifications)
+	}
+
+	if response := handleRequest(t, ctx, server, "logging/setLevel", map[string]any{"level": "warning"}); response["error"] != nil {
+		t.Fatalf("Unexpected logging/setLevel error: %v", response["error"])
+	}
+
+	callKubectl("kubectl get pods; rm -rf /")
+	notifications := drainNotifications(session.fakeSession)
+	if len(notifications) != 1 {
+		t.Fatalf("Expected one log message for the denied command, got %+v", notifications)
+	}
+	fields := notifications[0].Params.AdditionalFields
+	data, _ := fields["data"].(map[string]any)
+	if notifications[0].Method != "notifications/message" || fields["level"] != mcpgo.LoggingLevelWarning {
+		t.Errorf("Unexpected notification: %+v", notifications[0])
+	}
+	if data["message"] != "Command denied by security validation" || data["tool"] != "kubectl" ||
+		!strings.HasPrefix(data["error"].(string), "Security violation") {
+		t.Errorf("Unexpected log data: %v", data)
+	}
+
+	// Successful calls log at info, below the requested level.
+	callKubectl("kubectl get pods")
+	if notifications := drainNotifications(session.fakeSession); len(notifications) != 0 {
+		t.Errorf("Expected no log messages for a successful call at warning level, got %+v", notifications)
+	}
+
+	if response := handleRequest(t, ctx, server, "logging/setLevel", map[string]any{"level": "info"}); response["error"] != nil {
+		t.Fatalf("Unexpected logging/setLevel error: %v", response["error"])
+	}
+	callKubectl("kubectl get pods")
+	notifications = drainNotifications(session.fakeSession)
+	if len(notifications) != 1 {
+		t.Fatalf("Expected one log message for the tool call at info level, got %+v", notifications)
+	}
+	if data, _ := notifications[0].Params.AdditionalFields["data"].(map[string]any); data["message"] != "Received tool call" {
+		t.Errorf("Unexpected log data: %v", data)
+	}
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+)
+
+const nodePodsJSON = `{"items": [
+  {"metadata": {"name": "web-1", "namespace": "default",
+     "ownerReferences": [{"kind": "ReplicaSet", "name": "web-7d4b9", "controller": true}]},
+   "spec": {"nodeName": "node-a", "containers": [{"name": "app"}]},
+   "status": {"phase": "Running"}},
+  {"metadata": {"name": "fluentd-x2", "namespace": "logging",
+     "ownerReferences": [{"kind": "DaemonSet", "name": "fluentd", "controller": true}]},
+   "spec": {"nodeName": "node-a", "containers": [{"name": "fluentd"}]},
+   "status": {"phase": "Running"}},
+  {"metadata": {"name": "kube-apiserver-node-a", "namespace": "kube-system",
+     "annotations": {"kubernetes.io/config.mirror": "abc123"}},
+   "spec": {"nodeName": "node-a", "containers": [{"name": "apiserver"}]},
+   "status": {"phase": "Running"}},
+  {"metadata": {"name": "cache-0", "namespace": "default",
+     "ownerReferences": [{"kind": "StatefulSet", "name": "cache", "controller": true}]},
+   "spec": {"nodeName": "node-a", "containers": [{"name": "redis"}], "volumes": [{"name": "tmp", "emptyDir": {}}]},
+   "status": {"phase": "Running"}},
+  {"metadata": {"name": "debug", "namespace": "default"},
+   "spec": {"nodeName": "node-a", "containers": [{"name": "shell"}]},
+   "status": {"phase": "Running"}}
+]}`
+
+func TestNodeMaintenanceTool_CheckModifiesResource(t *testing.T) {
+	tool := &kubectl.NodeMaintenanceTool{}
+	tests := map[string]string{
+		"preview":  "no",
+		"cordon":   "yes",
+		"drain":    "yes",
+		"uncordon": "yes",
+		"reboot":   "unknown",
+	}
+	for action, expected := range tests {
+		if got := tool.CheckModifiesResource(map[string]any{"action": action}); got != expected {
+			t.Errorf("CheckModifiesResource(%q) = %q, want %q", action, got, expected)
+		}
+	}
+}
+
+func TestNodeMaintenanceTool_Run(t *testing.T) {
+	tool := &kubectl.NodeMaintenanceTool{}
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		tests := []struct {
+			name string
+			args map[string]any
+			want string
+		}{
+			{"Missing node", map[string]any{"action": "cordon"}, "node name is required"},
+			{"Invalid node", map[string]any{"node": "node-a;reboot", "action": "cordon"}, "invalid node name"},
+			{"Unknown action", map[string]any{"node": "node-a", "action": "reboot"}, "action must be one of"},
+			{"Drain without timeout", map[string]any{"node": "node-a", "action": "drain"}, "timeout_seconds is required"},
+			{"Drain timeout too long", map[string]any{"node": "node-a", "action": "drain", "timeout_seconds": float64(86400)}, "timeout_seconds must be between"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				result, err := tool.Run(toolContext(t), tt.args)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if res := result.(*kubectl.NodeMaintenanceResult); !strings.Contains(res.Error, tt.want) {
+					t.Errorf("Expected error containing %q, got %q", tt.want, res.Error)
+				}
+			})
+		}
+	})
+
+	t.Run("Preview classifies pods", func(t *testing.T) {
+		fakeKubectl(t, "cat <<'OUT'\n"+nodePodsJSON+"\nOUT")
+
+		result, err := tool.Run(toolContext(t), map[string]any{"node": "node-a", "action": "preview"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res := result.(*kubectl.NodeMaintenanceResult)
+		if res.Error != "" {
+			t.Fatalf("Unexpected error result: %s", res.Error)
+		}
+
+		actions := make(map[string]string)
+		for _, p := range res.Pods {
+			actions[p.Name] = p.Action
+		}
+		expected := map[string]string{
+			"web-1":                 "evict",
+			"fluentd-x2":            "skip",
+			"kube-apiserver-node-a": "skip",
+			"cache-0":               "block",
+			"debug":                 "block",
+		}
+		for name, action := range expected {
+			if actions[name] != action {
+				t.Errorf("Expected %s to be %q, got %q", name, action, actions[name])
+			}
+		}
+		if res.Evicted != 1 || res.Blocking != 2 {
+			t.Errorf("Expected 1 evicted and 2 blocking, got %d and %d", res.Evicted, res.Blocking)
+		}
+	})
+
+	t.Run("Drain is refused while pods block it", func(t *testing.T) {
+		logFile := fakeKubectl(t, "cat <<'OUT'\n"+nodePodsJSON+"\nOUT")
+
+		result, err := tool.Run(toolContext(t), map[string]any{
+			"node":                 "node-a",
+			"action":               "drain",
+			"timeout_seconds":      float64(300),
+			"delete_emptydir_data": true,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res := result.(*kubectl.NodeMaintenanceResult)
+		if !strings.Contains(res.Error, "refusing to drain: 1 pod(s)") {
+			t.Errorf("Expected drain to be refused because of the unmanaged pod, got %q", res.Error)
+		}
+		for _, call := range invocations(t, logFile) {
+			if strings.HasPrefix(call, "drain") {
+				t.Errorf("kubectl drain should not have been run: %q", call)
+			}
+		}
+	})
+
+	t.Run("Drain uses safe defaults", func(t *testing.T) {
+		logFile := fakeKubectl(t, `case "$1" in
+  get) echo '{"items": []}' ;;
+  drain) echo "node/node-a drained" ;;
+esac`)
+
+		result, err := tool.Run(toolContext(t), map[string]any{
+			"node":            "node-a",
+			"action":          "drain",
+			"timeout_seconds": float64(300),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res := result.(*kubectl.NodeMaintenanceResult)
+		if res.Error != "" || !strings.Contains(res.Output, "drained") {
+			t.Fatalf("Unexpected result: %+v", res)
+		}
+
+		calls := invocations(t, logFile)
+		if last := calls[len(calls)-1]; last != "drain node-a --ignore-daemonsets --timeout=300s" {
+			t.Errorf("Unexpected drain invocation: %q", last)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+const podListYAML = `apiVersion: v1
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    annotations:
+      kubectl.kubernetes.io/last-applied-configuration: |
+        {"apiVersion":"v1","kind":"Pod","metadata":{"name":"web-1"}}
+    managedFields:
+    - apiVersion: v1
+      fieldsType: FieldsV1
+      manager: kubectl-client-side-apply
+      operation: Update
+    name: web-1
+    namespace: shop
+    resourceVersion: "123456"
+    uid: 0b6d0d5c-3a3e-4a57-9c38-8f1f3c0e2d11
+  spec:
+    replicas: 3
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    annotations:
+      kubectl.kubernetes.io/last-applied-configuration: "{}"
+      team: payments
+    name: web-2
+    namespace: shop
+    uid: 9a1f0e52-d6b4-4f36-8c2e-7d4b1f0a3c22
+kind: List
+metadata:
+  resourceVersion: ""
+`
+
+func TestNormalizer(t *testing.T) {
+	normalizer, err := kubectl.NewNormalizer(nil, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("Strips noise from YAML lists", func(t *testing.T) {
+		out, stats := normalizer.Normalize([]byte(podListYAML), true)
+		expected := `apiVersion: v1
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: web-1
+    namespace: shop
+  spec:
+    replicas: 3
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    annotations:
+      team: payments
+    name: web-2
+    namespace: shop
+kind: List
+`
+		if string(out) != expected {
+			t.Errorf("Unexpected output:\n%s", out)
+		}
+		if stats == nil || stats.StrippedFields != 7 || stats.BytesBefore != len(podListYAML) || stats.BytesAfter != len(expected) {
+			t.Fatalf("Unexpected stats: %+v", stats)
+		}
+		if stats.EstimatedTokensSaved != (len(podListYAML)-len(expected))/4 {
+			t.Errorf("Unexpected token estimate: %d", stats.EstimatedTokensSaved)
+		}
+	})
+
+	t.Run("JSON keeps kubectl's layout", func(t *testing.T) {
+		input := `{"kind": "Pod", "metadata": {"name": "web-1", "uid": "abc"}, "spec": {"cmd": "a && b", "port": 8080}}`
+		out, stats := normalizer.Normalize([]byte(input), false)
+		expected := "{\n    \"kind\": \"Pod\",\n    \"metadata\": {\n        \"name\": \"web-1\"\n    },\n" +
+			"    \"spec\": {\n        \"cmd\": \"a && b\",\n        \"port\": 8080\n    }\n}\n"
+		if string(out) != expected || stats.StrippedFields != 1 {
+			t.Errorf("Unexpected output (%+v):\n%s", stats, out)
+		}
+	})
+
+	t.Run("Compact JSON", func(t *testing.T) {
+		compact, err := kubectl.NewNormalizer([]string{"metadata.namespace", "spec"}, true)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		out, _ := compact.Normalize([]byte(podListYAML), true)
+		if !strings.HasPrefix(string(out), `{"apiVersion":"v1","items":[{"apiVersion":"v1","kind":"Pod","metadata":{"annotations":`) ||
+			strings.Contains(string(out), "replicas") || strings.Contains(string(out), `"namespace"`) || strings.Count(string(out), "\n") != 1 {
+			t.Errorf("Unexpected output:\n%s", out)
+		}
+	})
+
+	t.Run("Other output is unchanged", func(t *testing.T) {
+		for _, input := range []string{"NAME    READY\nweb-1   1/1\n", "[1, 2]", ""} {
+			out, stats := normalizer.Normalize([]byte(input), false)
+			if string(out) != input || stats != nil {
+				t.Errorf("Expected %q unchanged, got %q (%+v)", input, out, stats)
+			}
+		}
+	})
+
+	t.Run("Invalid field paths", func(t *testing.T) {
+		for _, field := range []string{"", "metadata..uid", `metadata.annotations["a.b`} {
+			if _, err := kubectl.NewNormalizer([]string{field}, false); err == nil {
+				t.Errorf("Expected %q to be rejected", field)
+			}
+		}
+	})
+}
+
+func TestKubectlTool_Normalize(t *testing.T) {
+	fakeKubectl(t, "cat <<'EOF'\n"+podListYAML+"\nEOF")
+	normalizer, err := kubectl.NewNormalizer(nil, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tool := &kubectl.KubectlTool{Normalizer: normalizer}
+
+	run := func(t *testing.T, args map[string]any) *types.ExecResult {
+		t.Helper()
+		result, err := tool.Run(toolContext(t), args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result.(*types.ExecResult)
+	}
+
+	t.Run("YAML output is normalized", func(t *testing.T) {
+		result := run(t, map[string]any{"command": "kubectl get pods -n shop -o yaml"})
+		if strings.Contains(result.Stdout, "managedFields") || result.Normalized == nil || result.Normalized.EstimatedTokensSaved == 0 {
+			t.Errorf("Expected normalized output, got %+v", result)
+		}
+	})
+
+	t.Run("Table output is left alone", func(t *testing.T) {
+		result := run(t, map[string]any{"command": "kubectl get pods -n shop"})
+		if result.Stdout != podListYAML+"\n" || result.Normalized != nil {
+			t.Errorf("Expected raw output, got %+v", result)
+		}
+	})
+
+	t.Run("Filters see the raw objects", func(t *testing.T) {
+		fakeKubectl(t, `echo '{"items": [{"metadata": {"name": "web-1", "uid": "abc", "resourceVersion": "42", "managedFields": []}}]}'`)
+		result := run(t, map[string]any{"command": "kubectl get pods", "filter": ".items[].metadata.resourceVersion"})
+		if result.Error != "" || compactJSON(result.Stdout) != `"42"` || result.Normalized != nil {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+		result = run(t, map[string]any{"command": "kubectl get pods", "filter": ".items[0].metadata.uid"})
+		if result.Error != "" || compactJSON(result.Stdout) != `"abc"` {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+func runPaged(t *testing.T, store *kubectl.OutputStore, limits types.OutputLimits) *types.ExecResult {
+	t.Helper()
+
+	tool := &kubectl.KubectlTool{OutputLimits: limits, Pages: store}
+	result, err := tool.Run(toolContext(t), map[string]any{"command": "kubectl get pods -A"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return result.(*types.ExecResult)
+}
+
+func fetchPage(t *testing.T, store *kubectl.OutputStore, cursor string) *kubectl.FetchOutputResult {
+	t.Helper()
+
+	result, err := (&kubectl.FetchOutputTool{Store: store}).Run(toolContext(t), map[string]any{"cursor": cursor})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return result.(*kubectl.FetchOutputResult)
+}
+
+func TestOutputPages(t *testing.T) {
+	// 100 lines of 10 bytes each: "line-0001\n" ... "line-0100\n".
+	fakeKubectl(t, `i=1; while [ $i -le 100 ]; do printf 'line-%04d\n' $i; i=$((i+1)); done`)
+
+	t.Run("Output within limits is returned whole", func(t *testing.T) {
+		store := kubectl.NewOutputStore(t.TempDir(), time.Minute)
+		result := runPaged(t, store, types.OutputLimits{MaxBytes: 1000})
+		if len(result.Stdout) != 1000 || result.NextCursor != "" || result.Truncated || store.Entries() != 0 {
+			t.Errorf("Expected whole output without cursor, got len=%d cursor=%q entries=%d", len(result.Stdout), result.NextCursor, store.Entries())
+		}
+	})
+
+	t.Run("Pages reconstruct the output", func(t *testing.T) {
+		store := kubectl.NewOutputStore(t.TempDir(), time.Minute)
+		result := runPaged(t, store, types.OutputLimits{MaxBytes: 256, MaxLines: 30})
+
+		if result.Truncated || result.TotalBytes != 1000 || result.NextCursor == "" || !strings.Contains(result.Hint, "fetch_output") {
+			t.Fatalf("Expected a first page with cursor, got truncated=%v total=%d cursor=%q hint=%q",
+				result.Truncated, result.TotalBytes, result.NextCursor, result.Hint)
+		}
+		// 256 bytes end mid-line, so the page stops after the 25th line.
+		if len(result.Stdout) != 250 || !strings.HasSuffix(result.Stdout, "line-0025\n") {
+			t.Errorf("Expected the first page to end at a line break, got:\n%s", result.Stdout)
+		}
+
+		output, cursor, pages := result.Stdout, result.NextCursor, 1
+		for cursor != "" {
+			page := fetchPage(t, store, cursor)
+			if page.Error != "" {
+				t.Fatalf("Unexpected error fetching page: %s", page.Error)
+			}
+			if page.Offset != int64(len(output)) || page.TotalBytes != 1000 {
+				t.Errorf("Expected offset %d of 1000, got %d of %d", len(output), page.Offset, page.TotalBytes)
+			}
+			output += page.Output
+			cursor = page.NextCursor
+			pages++
+		}
+
+		var expected strings.Builder
+		for i := 1; i <= 100; i++ {
+			fmt.Fprintf(&expected, "line-%04d\n", i)
+		}
+		if output != expected.String() {
+			t.Errorf("Pages did not reconstruct the output:\n%s", output)
+		}
+		if pages != 4 {
+			t.Errorf("Expected 4 pages, got %d", pages)
+		}
+	})
+
+	t.Run("Invalid cursors are rejected", func(t *testing.T) {
+		store := kubectl.NewOutputStore(t.TempDir(), time.Minute)
+		result := runPaged(t, store, types.OutputLimits{MaxLines: 10})
+		id, _, _ := strings.Cut(result.NextCursor, ".")
+
+		for _, cursor := range []string{"", "nope", id + ".x", id + ".-1", id + ".5000", "0000." + "10"} {
+			if page := fetchPage(t, store, cursor); !strings.Contains(page.Error, "invalid or has expired") {
+				t.Errorf("Expected cursor %q to be rejected, got %+v", cursor, page)
+			}
+		}
+	})
+
+	t.Run("Stored output expires", func(t *testing.T) {
+		store := kubectl.NewOutputStore(t.TempDir(), 50*time.Millisecond)
+		result := runPaged(t, store, types.OutputLimits{MaxLines: 10})
+		if store.Entries() != 1 {
+			t.Fatalf("Expected 1 stored output, got %d", store.Entries())
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		if page := fetchPage(t, store, result.NextCursor); !strings.Contains(page.Error, "expired") {
+			t.Errorf("Expected the cursor to have expired, got %+v", page)
+		}
+		if store.Entries() != 0 {
+			t.Errorf("Expected expired output to be removed, got %d entries", store.Entries())
+		}
+	})
+
+	t.Run("Close removes stored output", func(t *testing.T) {
+		workDir := t.TempDir()
+		store := kubectl.NewOutputStore(workDir, time.Minute)
+		result := runPaged(t, store, types.OutputLimits{MaxLines: 10})
+
+		if err := store.Close(); err != nil {
+			t.Fatalf("Unexpected error closing store: %v", err)
+		}
+		if page := fetchPage(t, store, result.NextCursor); page.Error == "" {
+			t.Error("Expected the cursor to be invalid after Close")
+		}
+		if matches, _ := filepath.Glob(filepath.Join(workDir, "output-*")); len(matches) != 0 {
+			t.Errorf("Expected the output directory to be removed, found %v", matches)
+		}
+	})
+
+	t.Run("Unwritable store falls back to truncation", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		if err := os.WriteFile(file, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		store := kubectl.NewOutputStore(filepath.Join(file, "sub"), time.Minute)
+		result := runPaged(t, store, types.OutputLimits{MaxLines: 4})
+		if !result.Truncated || result.NextCursor != "" || !strings.Contains(result.Stdout, "bytes truncated") {
+			t.Errorf("Expected truncated output, got truncated=%v cursor=%q:\n%s", result.Truncated, result.NextCursor, result.Stdout)
+		}
+	})
+}
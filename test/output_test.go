@@ -0,0 +1,95 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+func runWithLimits(t *testing.T, limits types.OutputLimits) *types.ExecResult {
+	t.Helper()
+
+	result, err := (&kubectl.KubectlTool{OutputLimits: limits}).Run(toolContext(t), map[string]any{"command": "kubectl get pods -A"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return result.(*types.ExecResult)
+}
+
+func TestOutputLimits(t *testing.T) {
+	// 100 lines of 10 bytes each: "line-0001\n" ... "line-0100\n".
+	fakeKubectl(t, `i=1; while [ $i -le 100 ]; do printf 'line-%04d\n' $i; i=$((i+1)); done`)
+
+	t.Run("Within limits", func(t *testing.T) {
+		result := runWithLimits(t, types.OutputLimits{MaxBytes: 1000, MaxLines: 100})
+		if result.Truncated || result.Hint != "" || len(result.Stdout) != 1000 || result.TotalBytes != 1000 {
+			t.Errorf("Expected untruncated output, got truncated=%v total=%d len=%d", result.Truncated, result.TotalBytes, len(result.Stdout))
+		}
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		result := runWithLimits(t, types.OutputLimits{})
+		if result.Truncated || len(result.Stdout) != 1000 {
+			t.Errorf("Expected untruncated output, got truncated=%v len=%d", result.Truncated, len(result.Stdout))
+		}
+	})
+
+	t.Run("Byte limit keeps head and tail", func(t *testing.T) {
+		result := runWithLimits(t, types.OutputLimits{MaxBytes: 200})
+		expected := ""
+		for i := 1; i <= 10; i++ {
+			expected += fmt.Sprintf("line-%04d\n", i)
+		}
+		expected += "[... 800 bytes truncated ...]\n"
+		for i := 91; i <= 100; i++ {
+			expected += fmt.Sprintf("line-%04d\n", i)
+		}
+		if result.Stdout != expected {
+			t.Errorf("Unexpected output:\n%s", result.Stdout)
+		}
+		if !result.Truncated || result.TotalBytes != 1000 || !strings.Contains(result.Hint, "Narrow the query") {
+			t.Errorf("Expected truncation metadata, got truncated=%v total=%d hint=%q", result.Truncated, result.TotalBytes, result.Hint)
+		}
+	})
+
+	t.Run("Line limit keeps head and tail", func(t *testing.T) {
+		result := runWithLimits(t, types.OutputLimits{MaxLines: 4})
+		expected := "line-0001\nline-0002\n[... 960 bytes truncated ...]\nline-0099\nline-0100\n"
+		if result.Stdout != expected || !result.Truncated {
+			t.Errorf("Unexpected output (truncated=%v):\n%s", result.Truncated, result.Stdout)
+		}
+	})
+
+	t.Run("Line limit over a long stream", func(t *testing.T) {
+		fakeKubectl(t, `seq 1 200000; printf 'last'`)
+		result := runWithLimits(t, types.OutputLimits{MaxLines: 4})
+		if !strings.HasPrefix(result.Stdout, "1\n2\n[... ") || !strings.HasSuffix(result.Stdout, " bytes truncated ...]\n200000\nlast") {
+			t.Errorf("Unexpected output (truncated=%v):\n%s", result.Truncated, result.Stdout)
+		}
+		kept := int64(len("1\n2\n200000\nlast"))
+		if dropped := fmt.Sprintf("[... %d bytes truncated ...]", result.TotalBytes-kept); !strings.Contains(result.Stdout, dropped) {
+			t.Errorf("Expected %q in the output:\n%s", dropped, result.Stdout)
+		}
+	})
+
+	t.Run("Progress reports every line despite limits", func(t *testing.T) {
+		var lines []string
+		ctx := context.WithValue(toolContext(t), types.ProgressKey, types.ProgressFunc(func(line string) {
+			lines = append(lines, line)
+		}))
+		result, err := (&kubectl.KubectlTool{OutputLimits: types.OutputLimits{MaxLines: 4}}).Run(ctx, map[string]any{"command": "kubectl get pods -A"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(lines) != 100 || lines[99] != "line-0100" {
+			t.Errorf("Expected all 100 lines as progress, got %d", len(lines))
+		}
+		if !result.(*types.ExecResult).Truncated {
+			t.Error("Expected the result to be truncated")
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kubectl-go-mcp-server/internal/mcp"
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+func TestRunKubectlCommand_Progress(t *testing.T) {
+	fakeKubectl(t, `echo 'Waiting for deployment "web" rollout to finish: 1 of 2 updated replicas are available...'
+echo 'warning: slow rollout' >&2
+echo
+printf 'deployment "web" successfully rolled out'`)
+
+	var mu sync.Mutex
+	var lines []string
+	ctx := context.WithValue(context.Background(), types.ProgressKey, types.ProgressFunc(func(message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, message)
+	}))
+
+	result, err := kubectl.RunKubectlCommand(ctx, "kubectl rollout status deployment/web", t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("Unexpected result error: %s", result.Error)
+	}
+
+	expected := []string{
+		`Waiting for deployment "web" rollout to finish: 1 of 2 updated replicas are available...`,
+		"warning: slow rollout",
+		`deployment "web" successfully rolled out`,
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected progress lines %q, got %q", expected, lines)
+	}
+	for _, line := range expected {
+		if !strings.Contains(result.Stdout, line) {
+			t.Errorf("Expected output to contain %q, got %q", line, result.Stdout)
+		}
+	}
+}
+
+func TestRunKubectlCommand_Cancelled(t *testing.T) {
+	fakeKubectl(t, `echo 'waiting for the condition'; exec sleep 30`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	ctx = context.WithValue(ctx, types.ProgressKey, types.ProgressFunc(func(string) {}))
+
+	start := time.Now()
+	result, err := kubectl.RunKubectlCommand(ctx, "kubectl wait --for=condition=Ready pod/web", t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the command to be killed on cancellation, took %s", elapsed)
+	}
+	if !strings.Contains(result.Error, "cancelled") {
+		t.Errorf("Expected a cancellation error, got %q", result.Error)
+	}
+	if !strings.Contains(result.Stdout, "waiting for the condition") {
+		t.Errorf("Expected output before cancellation to be kept, got %q", result.Stdout)
+	}
+}
+
+func TestToolCallProgressNotifications(t *testing.T) {
+	fakeKubectl(t, `echo 'node/worker-1 cordoned'`)
+
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	session := newFakeSession("session-1")
+	ctx := server.GetMCPServer().WithContext(context.Background(), session)
+	if err := server.GetMCPServer().RegisterSession(ctx, session); err != nil {
+		t.Fatalf("Failed to register session: %v", err)
+	}
+
+	call := func(meta map[string]any) map[string]any {
+		params := map[string]any{
+			"name":      "kubectl",
+			"arguments": map[string]any{"command": "kubectl cordon worker-1"},
+		}
+		if meta != nil {
+			params["_meta"] = meta
+		}
+		return handleRequest(t, ctx, server, "tools/call", params)
+	}
+
+	if response := call(map[string]any{"progressToken": "call-1"}); response["error"] != nil {
+		t.Fatalf("Unexpected tools/call error: %v", response["error"])
+	}
+	select {
+	case notification := <-session.notifications:
+		fields := notification.Params.AdditionalFields
+		if notification.Method != "notifications/progress" || fields["progressToken"] != "call-1" ||
+			fields["message"] != "node/worker-1 cordoned" {
+			t.Errorf("Unexpected notification: %+v", notification)
+		}
+	default:
+		t.Fatal("Expected a progress notification")
+	}
+
+	if response := call(nil); response["error"] != nil {
+		t.Fatalf("Unexpected tools/call error: %v", response["error"])
+	}
+	select {
+	case notification := <-session.notifications:
+		t.Errorf("Expected no notification without a progress token, got %+v", notification)
+	default:
+	}
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/internal/config"
+	"kubectl-go-mcp-server/internal/mcp"
+)
+
+func TestBuiltinPrompts(t *testing.T) {
+	prompts, err := mcp.BuiltinPrompts()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	args := map[string]map[string]string{
+		"capacity-report":       {},
+		"explain-rbac-for-user": {"user": "system:serviceaccount:ci:deployer"},
+		"review-manifest":       {"manifest": "apiVersion: v1\nkind: ConfigMap"},
+		"troubleshoot-pod":      {"namespace": "shop", "pod": "web-1"},
+	}
+	if len(prompts) != len(args) {
+		t.Errorf("Expected %d built-in prompts, got %d", len(args), len(prompts))
+	}
+
+	for _, prompt := range prompts {
+		t.Run(prompt.Name, func(t *testing.T) {
+			promptArgs, ok := args[prompt.Name]
+			if !ok {
+				t.Fatalf("Unexpected prompt %s", prompt.Name)
+			}
+			if prompt.Description == "" {
+				t.Error("Expected a description")
+			}
+			text, err := prompt.Render(promptArgs)
+			if err != nil {
+				t.Fatalf("Unexpected render error: %v", err)
+			}
+			for _, value := range promptArgs {
+				if !strings.Contains(text, value) {
+					t.Errorf("Expected rendered prompt to contain %q:\n%s", value, text)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePromptTemplate(t *testing.T) {
+	valid := "---\ndescription: Check a service\narguments:\n  - name: service\n    required: true\n  - name: namespace\n---\nCheck {{.service}}{{if .namespace}} in {{.namespace}}{{end}}.\n"
+
+	prompt, err := mcp.ParsePromptTemplate("check-service", []byte(valid))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if prompt.Name != "check-service" || len(prompt.Arguments) != 2 || !prompt.Arguments[0].Required {
+		t.Errorf("Unexpected prompt: %+v", prompt)
+	}
+
+	text, err := prompt.Render(map[string]string{"service": "web"})
+	if err != nil || text != "Check web." {
+		t.Errorf("Render() = %q, %v", text, err)
+	}
+	if _, err := prompt.Render(map[string]string{"namespace": "shop"}); err == nil || !strings.Contains(err.Error(), `requires argument "service"`) {
+		t.Errorf("Expected missing argument error, got %v", err)
+	}
+
+	invalid := map[string]string{
+		"No front matter":    "Check {{.service}}",
+		"Unterminated":       "---\ndescription: x\nCheck",
+		"Unknown field":      "---\ndescripton: x\n---\nCheck",
+		"Invalid name":       "---\nname: Check Service\n---\nCheck",
+		"Duplicate argument": "---\narguments:\n  - name: a\n  - name: a\n---\nCheck",
+		"Invalid template":   "---\ndescription: x\n---\nCheck {{.service",
+	}
+	for name, data := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := mcp.ParsePromptTemplate("check-service", []byte(data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	t.Run("Undeclared argument", func(t *testing.T) {
+		prompt, err := mcp.ParsePromptTemplate("check-service", []byte("---\ndescription: x\n---\nCheck {{.service}}"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := prompt.Render(map[string]string{"service": "web"}); err == nil {
+			t.Error("Expected undeclared arguments to be rejected when rendering")
+		}
+	})
+}
+
+func TestServerPrompts(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"check-ingress.tmpl":    "---\ndescription: Check an ingress\narguments:\n  - name: ingress\n    required: true\n---\nCheck ingress {{.ingress}} with the kubectl tool.\n",
+		"troubleshoot-pod.tmpl": "---\ndescription: Team runbook\narguments:\n  - name: pod\n    required: true\n---\nFollow the runbook for {{.pod}}.\n",
+		"README.md":             "not a prompt",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Prompts.Dir = dir
+	server, err := mcp.NewServer("", t.TempDir(), mcp.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("List", func(t *testing.T) {
+		response := handleRequest(t, ctx, server, "prompts/list", map[string]any{})
+		result := response["result"].(map[string]any)
+		names := make(map[string]string)
+		for _, p := range result["prompts"].([]any) {
+			prompt := p.(map[string]any)
+			names[prompt["name"].(string)] = prompt["description"].(string)
+		}
+		if len(names) != 5 {
+			t.Errorf("Expected 4 built-in and 1 new custom prompt, got %v", names)
+		}
+		if names["check-ingress"] != "Check an ingress" || names["troubleshoot-pod"] != "Team runbook" {
+			t.Errorf("Expected custom prompts to be listed and override built-ins, got %v", names)
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		response := handleRequest(t, ctx, server, "prompts/get", map[string]any{
+			"name":      "check-ingress",
+			"arguments": map[string]any{"ingress": "shop-web"},
+		})
+		result, ok := response["result"].(map[string]any)
+		if !ok {
+			t.Fatalf("Expected a result, got %v", response)
+		}
+		message := result["messages"].([]any)[0].(map[string]any)
+		text := message["content"].(map[string]any)["text"].(string)
+		if message["role"] != "user" || text != "Check ingress shop-web with the kubectl tool." {
+			t.Errorf("Unexpected message: %v", message)
+		}
+	})
+
+	t.Run("Get without required argument", func(t *testing.T) {
+		response := handleRequest(t, ctx, server, "prompts/get", map[string]any{"name": "check-ingress"})
+		if response["error"] == nil {
+			t.Errorf("Expected an error, got %v", response)
+		}
+	})
+
+	t.Run("Invalid prompt file fails server creation", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(dir, "broken.tmpl"), []byte("no front matter"), 0o644); err != nil {
+			t.Fatalf("Failed to write broken.tmpl: %v", err)
+		}
+		if _, err := mcp.NewServer("", t.TempDir(), mcp.WithConfig(cfg)); err == nil || !strings.Contains(err.Error(), "broken.tmpl") {
+			t.Errorf("Expected an error naming broken.tmpl, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+)
+
+func TestParseCPUMillicores(t *testing.T) {
+	tests := []struct {
+		quantity string
+		expected int64
+		wantErr  bool
+	}{
+		{"250m", 250, false},
+		{"1", 1000, false},
+		{"0.5", 500, false},
+		{"1500000n", 2, false},
+		{"2500u", 3, false},
+		{"", 0, true},
+		{"abc", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.quantity, func(t *testing.T) {
+			got, err := kubectl.ParseCPUMillicores(tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCPUMillicores(%q) error = %v, wantErr %v", tt.quantity, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseCPUMillicores(%q) = %d, want %d", tt.quantity, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseMemoryBytes(t *testing.T) {
+	tests := []struct {
+		quantity string
+		expected int64
+		wantErr  bool
+	}{
+		{"128Mi", 128 << 20, false},
+		{"1.5Gi", 3 << 29, false},
+		{"512Ki", 512 << 10, false},
+		{"1G", 1000000000, false},
+		{"100k", 100000, false},
+		{"2e3", 2000, false},
+		{"4096", 4096, false},
+		{"12Xi", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.quantity, func(t *testing.T) {
+			got, err := kubectl.ParseMemoryBytes(tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMemoryBytes(%q) error = %v, wantErr %v", tt.quantity, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseMemoryBytes(%q) = %d, want %d", tt.quantity, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/internal/config"
+	"kubectl-go-mcp-server/internal/mcp"
+	"kubectl-go-mcp-server/pkg/kubectl"
+)
+
+const topNodesOutput = `NAME     CPU(cores)   CPU%   MEMORY(bytes)   MEMORY%
+node-a   1250m        62%    6144Mi          81%
+node-b   300m         15%    2Gi             26%
+node-c   <unknown>    <unknown>   <unknown>   <unknown>
+`
+
+const topPodsOutput = `NAMESPACE   NAME    CPU(cores)   MEMORY(bytes)
+default     web-1   450m         200Mi
+default     web-2   50m          900Mi
+batch       job-1   10m          10Mi
+`
+
+const podsJSON = `{"items": [
+  {"metadata": {"name": "web-1", "namespace": "default"},
+   "spec": {"containers": [
+     {"name": "app", "resources": {"requests": {"cpu": "250m", "memory": "256Mi"}, "limits": {"cpu": "500m", "memory": "1Gi"}}},
+     {"name": "sidecar", "resources": {"requests": {"cpu": "50m"}, "limits": {"cpu": "100m", "memory": "128Mi"}}}
+   ]}},
+  {"metadata": {"name": "web-2", "namespace": "default"},
+   "spec": {"containers": [
+     {"name": "app", "resources": {"requests": {"cpu": "100m", "memory": "1Gi"}}}
+   ]}}
+]}`
+
+func TestParseTopOutput(t *testing.T) {
+	t.Run("Nodes", func(t *testing.T) {
+		items, err := kubectl.ParseTopOutput(topNodesOutput)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("Expected 2 nodes with metrics, got %d", len(items))
+		}
+		if items[0].CPUMillicores != 1250 || items[0].MemoryBytes != 6144<<20 {
+			t.Errorf("Unexpected usage for node-a: %+v", items[0])
+		}
+		if items[0].MemoryPercent == nil || *items[0].MemoryPercent != 81 {
+			t.Errorf("Expected memory percent 81, got %v", items[0].MemoryPercent)
+		}
+	})
+
+	t.Run("Pods across namespaces", func(t *testing.T) {
+		items, err := kubectl.ParseTopOutput(topPodsOutput)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(items) != 3 || items[2].Namespace != "batch" || items[2].Name != "job-1" {
+			t.Errorf("Unexpected pods: %+v", items)
+		}
+	})
+
+	t.Run("Metrics API unavailable", func(t *testing.T) {
+		if _, err := kubectl.ParseTopOutput("error: Metrics API not available"); err == nil {
+			t.Error("Expected error for output without a table")
+		}
+	})
+}
+
+func TestSortResourceUsage(t *testing.T) {
+	items, err := kubectl.ParseTopOutput(topPodsOutput)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	kubectl.SortResourceUsage(items, "memory")
+	if items[0].Name != "web-2" {
+		t.Errorf("Expected web-2 first by memory, got %s", items[0].Name)
+	}
+	kubectl.SortResourceUsage(items, "name")
+	if items[0].Name != "job-1" {
+		t.Errorf("Expected batch/job-1 first by name, got %s", items[0].Name)
+	}
+}
+
+func TestResourceUsageTool(t *testing.T) {
+	tool := &kubectl.ResourceUsageTool{}
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		tests := []struct {
+			name string
+			args map[string]any
+			want string
+		}{
+			{"Missing target", map[string]any{}, "target must be"},
+			{"Bad sort", map[string]any{"target": "pods", "sort_by": "age"}, "sort_by must be"},
+			{"Namespace for nodes", map[string]any{"target": "nodes", "namespace": "default"}, "only apply to pods"},
+			{"Set-based selector", map[string]any{"target": "pods", "selector": "env in (a)"}, "invalid label selector"},
+			{"Fractional top", map[string]any{"target": "pods", "top": 1.5}, "must be an integer"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				result, err := tool.Run(toolContext(t), tt.args)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if res := result.(*kubectl.ResourceUsageResult); !strings.Contains(res.Error, tt.want) {
+					t.Errorf("Expected error containing %q, got %q", tt.want, res.Error)
+				}
+			})
+		}
+	})
+
+	t.Run("Pods with utilization", func(t *testing.T) {
+		logFile := fakeKubectl(t, `case "$1" in
+  top) cat <<'OUT'
+`+topPodsOutput+`OUT
+  ;;
+  get) cat <<'OUT'
+`+podsJSON+`
+OUT
+  ;;
+esac`)
+
+		result, err := tool.Run(toolContext(t), map[string]any{
+			"target":              "pods",
+			"all_namespaces":      true,
+			"include_utilization": true,
+			"top":                 float64(2),
+			"threshold_percent":   float64(70),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res := result.(*kubectl.ResourceUsageResult)
+		if res.Error != "" {
+			t.Fatalf("Unexpected error result: %s", res.Error)
+		}
+		if res.Total != 3 || len(res.Items) != 2 {
+			t.Fatalf("Expected 2 of 3 items, got %d of %d", len(res.Items), res.Total)
+		}
+
+		web1 := res.Items[0]
+		if web1.Name != "web-1" || web1.CPURequestMillicores != 300 || web1.CPULimitMillicores != 600 {
+			t.Errorf("Unexpected requests/limits for web-1: %+v", web1)
+		}
+		if web1.MemoryLimitBytes != (1<<30)+(128<<20) {
+			t.Errorf("Expected summed memory limit, got %d", web1.MemoryLimitBytes)
+		}
+		if web1.CPULimitPercent == nil || *web1.CPULimitPercent != 75 || !web1.OverThreshold {
+			t.Errorf("Expected web-1 at 75%% of CPU limit and over threshold, got %v %v", web1.CPULimitPercent, web1.OverThreshold)
+		}
+
+		web2 := res.Items[1]
+		if web2.CPULimitMillicores != 0 || web2.MemoryLimitPercent != nil {
+			t.Errorf("web-2 has no limits, got %+v", web2)
+		}
+		if web2.MemoryRequestPercent == nil || *web2.MemoryRequestPercent != 87.9 || !web2.OverThreshold {
+			t.Errorf("Expected web-2 flagged against its memory request, got %v", web2.MemoryRequestPercent)
+		}
+		if res.OverThreshold != 2 {
+			t.Errorf("Expected 2 pods over threshold, got %d", res.OverThreshold)
+		}
+
+		calls := invocations(t, logFile)
+		if len(calls) != 2 || calls[0] != "top pods --all-namespaces" || calls[1] != "get pods --all-namespaces -o json" {
+			t.Errorf("Unexpected kubectl invocations: %v", calls)
+		}
+	})
+}
+
+func TestResourceUsageTool_ConfiguredThreshold(t *testing.T) {
+	fakeKubectl(t, "cat <<'OUT'\n"+topNodesOutput+"OUT")
+
+	cfg := config.DefaultConfig()
+	cfg.ResourceUsage.ThresholdPercent = 20
+	server, err := mcp.NewServer("", t.TempDir(), mcp.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	call := func(args map[string]any) map[string]any {
+		t.Helper()
+		response := handleRequest(t, context.Background(), server, "tools/call", map[string]any{"name": "resource_usage", "arguments": args})
+		result, _ := response["result"].(map[string]any)
+		structured, _ := result["structuredContent"].(map[string]any)
+		return structured
+	}
+
+	// node-b is at 26% memory, over the configured 20% but not the default.
+	if structured := call(map[string]any{"target": "nodes"}); structured["threshold_percent"] != float64(20) || structured["over_threshold"] != float64(2) {
+		t.Errorf("Expected the configured threshold to flag 2 nodes, got %v", structured)
+	}
+	if structured := call(map[string]any{"target": "nodes", "threshold_percent": float64(90)}); structured["threshold_percent"] != float64(90) || structured["over_threshold"] != float64(0) {
+		t.Errorf("Expected the call's threshold to win, got %v", structured)
+	}
+}
@@ -0,0 +1,205 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/internal/mcp"
+	"kubectl-go-mcp-server/pkg/kubectl"
+)
+
+const secretJSON = `{"apiVersion": "v1", "kind": "Secret",
+  "metadata": {"name": "db", "namespace": "shop",
+    "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{\"data\":{\"password\":\"aHVudGVyMg==\"}}"}},
+  "type": "Opaque", "data": {"password": "aHVudGVyMg=="}}`
+
+// handleRequest sends a JSON-RPC request through the server's MCP handler and
+// returns the decoded response.
+func handleRequest(t *testing.T, ctx context.Context, server *mcp.Server, method string, params map[string]any) map[string]any {
+	t.Helper()
+
+	request, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	response := server.GetMCPServer().HandleMessage(ctx, request)
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return decoded
+}
+
+func readResource(t *testing.T, server *mcp.Server, uri string) map[string]any {
+	t.Helper()
+	return handleRequest(t, context.Background(), server, "resources/read", map[string]any{"uri": uri})
+}
+
+func resourceText(t *testing.T, response map[string]any) string {
+	t.Helper()
+
+	result, ok := response["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected a result, got %v", response)
+	}
+	contents := result["contents"].([]any)
+	if len(contents) != 1 {
+		t.Fatalf("Expected one content item, got %d", len(contents))
+	}
+	return contents[0].(map[string]any)["text"].(string)
+}
+
+func TestParseResourceURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		want    kubectl.ObjectRef
+		wantErr bool
+	}{
+		{"k8s://prod/shop/deployments/web", kubectl.ObjectRef{Context: "prod", Namespace: "shop", Kind: "deployments", Name: "web"}, false},
+		{"k8s://_/_/nodes/node-a", kubectl.ObjectRef{Kind: "nodes", Name: "node-a"}, false},
+		{"k8s://admin%40prod/shop/pods", kubectl.ObjectRef{Context: "admin@prod", Namespace: "shop", Kind: "pods"}, false},
+		{"https://prod/shop/pods", kubectl.ObjectRef{}, true},
+		{"k8s://prod/pods", kubectl.ObjectRef{}, true},
+		{"k8s://prod/shop/pods/web/extra", kubectl.ObjectRef{}, true},
+		{"k8s://prod//pods", kubectl.ObjectRef{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			ref, err := mcp.ParseResourceURI(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResourceURI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ref != tt.want {
+				t.Errorf("ParseResourceURI() = %+v, want %+v", ref, tt.want)
+			}
+		})
+	}
+
+	ref := kubectl.ObjectRef{Context: "admin@prod", Namespace: "shop", Kind: "pods", Name: "web"}
+	if uri := mcp.ObjectURI(ref); uri != "k8s://admin%40prod/shop/pods/web" {
+		t.Errorf("ObjectURI() = %q", uri)
+	}
+	if parsed, err := mcp.ParseResourceURI(mcp.ObjectURI(ref)); err != nil || parsed != ref {
+		t.Errorf("Round trip gave %+v, %v", parsed, err)
+	}
+}
+
+func TestDiscoveryCache_GetCommand(t *testing.T) {
+	discovery := kubectl.NewDiscoveryCache(kubectl.DefaultDiscoveryRefreshInterval)
+
+	tests := []struct {
+		name    string
+		ref     kubectl.ObjectRef
+		want    string
+		wantErr string
+	}{
+		{"Namespaced object", kubectl.ObjectRef{Context: "prod", Namespace: "shop", Kind: "deploy", Name: "web"}, "kubectl get deployments.apps web -n shop --context=prod -o json", ""},
+		{"Cluster-scoped object", kubectl.ObjectRef{Kind: "nodes", Name: "node-a"}, "kubectl get nodes node-a -o json", ""},
+		{"List across namespaces", kubectl.ObjectRef{Kind: "pods"}, "kubectl get pods --all-namespaces -o json", ""},
+		{"Namespaced object without namespace", kubectl.ObjectRef{Kind: "pods", Name: "web"}, "", "a namespace is required"},
+		{"Cluster-scoped kind with namespace", kubectl.ObjectRef{Namespace: "shop", Kind: "nodes"}, "", "cluster-scoped"},
+		{"Unknown kind", kubectl.ObjectRef{Namespace: "shop", Kind: "widgets"}, "", "unknown resource type"},
+		{"Invalid context", kubectl.ObjectRef{Context: "prod;ls", Kind: "nodes"}, "", "invalid context name"},
+		{"Invalid name", kubectl.ObjectRef{Namespace: "shop", Kind: "pods", Name: "web$(id)"}, "", "invalid pod name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, _, err := discovery.GetCommand(tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if command != tt.want {
+				t.Errorf("GetCommand() = %q, want %q", command, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	var list map[string]any
+	if err := json.Unmarshal([]byte(`{"apiVersion": "v1", "kind": "List", "items": [`+secretJSON+`,
+		{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cfg"}, "data": {"mode": "fast"}}]}`), &list); err != nil {
+		t.Fatalf("Failed to decode fixture: %v", err)
+	}
+
+	kubectl.RedactSecrets(list)
+
+	data, _ := json.Marshal(list)
+	if strings.Contains(string(data), "aHVudGVyMg==") {
+		t.Errorf("Secret value leaked: %s", data)
+	}
+	if !strings.Contains(string(data), `"password":"\u003credacted\u003e"`) {
+		t.Errorf("Expected secret keys to be kept, got %s", data)
+	}
+	if !strings.Contains(string(data), `"mode":"fast"`) {
+		t.Errorf("Expected ConfigMap data to be untouched, got %s", data)
+	}
+}
+
+func TestServerResources(t *testing.T) {
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	t.Run("Object as YAML with secrets redacted", func(t *testing.T) {
+		logFile := fakeKubectl(t, "cat <<'OUT'\n"+secretJSON+"\nOUT")
+
+		text := resourceText(t, readResource(t, server, "k8s://prod/shop/secrets/db"))
+		if strings.Contains(text, "aHVudGVyMg==") {
+			t.Errorf("Secret value leaked: %s", text)
+		}
+		if !strings.Contains(text, "password: <redacted>") || !strings.Contains(text, "name: db") {
+			t.Errorf("Unexpected YAML:\n%s", text)
+		}
+		resourceText(t, readResource(t, server, "k8s://admin%40prod/shop/secrets/db"))
+		expected := []string{"get secrets db -n shop --context=prod -o json", "get secrets db -n shop --context=admin@prod -o json"}
+		if calls := invocations(t, logFile); !reflect.DeepEqual(calls, expected) {
+			t.Errorf("Expected kubectl invocations %v, got %v", expected, calls)
+		}
+	})
+
+	t.Run("List with object URIs", func(t *testing.T) {
+		fakeKubectl(t, `echo '{"items": [{"metadata": {"name": "web-1", "namespace": "shop"}}, {"metadata": {"name": "web-2", "namespace": "shop"}}]}'`)
+
+		var list mcp.ResourceList
+		if err := json.Unmarshal([]byte(resourceText(t, readResource(t, server, "k8s://_/shop/pods"))), &list); err != nil {
+			t.Fatalf("Failed to decode list: %v", err)
+		}
+		if list.Kind != "Pod" || list.Count != 2 || list.Items[1].URI != "k8s://_/shop/pods/web-2" {
+			t.Errorf("Unexpected list: %+v", list)
+		}
+	})
+
+	t.Run("Errors are reported", func(t *testing.T) {
+		fakeKubectl(t, `echo 'Error from server (NotFound): pods "web" not found'; exit 1`)
+
+		for _, uri := range []string{"k8s://_/shop/pods/web", "k8s://_/shop/widgets/web", "k8s://_/_/pods/web"} {
+			response := readResource(t, server, uri)
+			if _, ok := response["error"]; !ok {
+				t.Errorf("Expected an error for %s, got %v", uri, response)
+			}
+		}
+	})
+}
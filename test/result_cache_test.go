@@ -0,0 +1,170 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+func TestKubectlTool_ResultCache(t *testing.T) {
+	logFile := fakeKubectl(t, `case "$2" in broken) exit 1;; esac; echo "output of $*"`)
+	ctx := toolContext(t)
+
+	newTool := func(ttl time.Duration) *kubectl.KubectlTool {
+		return &kubectl.KubectlTool{Results: kubectl.NewResultCache(ttl)}
+	}
+	run := func(t *testing.T, tool *kubectl.KubectlTool, args map[string]any) *types.ExecResult {
+		t.Helper()
+		result, err := tool.Run(ctx, args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result.(*types.ExecResult)
+	}
+	// runs returns how many times kubectl ran for the commands.
+	runs := func(t *testing.T, tool *kubectl.KubectlTool, commands ...string) int {
+		t.Helper()
+		before := len(invocations(t, logFile))
+		for _, command := range commands {
+			run(t, tool, map[string]any{"command": command})
+		}
+		return len(invocations(t, logFile)) - before
+	}
+
+	t.Run("Equivalent commands share a result", func(t *testing.T) {
+		tool := newTool(time.Minute)
+		first := run(t, tool, map[string]any{"command": "kubectl get pods -n shop -o wide"})
+		if first.Cached {
+			t.Error("Expected the first result not to be cached")
+		}
+		before := len(invocations(t, logFile))
+		for _, command := range []string{"kubectl get pods -n shop -o wide", "kubectl  get pods --output=wide --namespace shop", "kubectl get -nshop pods -owide"} {
+			result := run(t, tool, map[string]any{"command": command})
+			if !result.Cached || result.Stdout != first.Stdout {
+				t.Errorf("Expected %q to be served from the cache, got %+v", command, result)
+			}
+		}
+		if n := len(invocations(t, logFile)) - before; n != 0 {
+			t.Errorf("Expected kubectl not to run again, ran %d times", n)
+		}
+	})
+
+	t.Run("Output processing is part of the key", func(t *testing.T) {
+		tool := newTool(time.Minute)
+		run(t, tool, map[string]any{"command": "kubectl logs web-1"})
+		if result := run(t, tool, map[string]any{"command": "kubectl logs web-1", "grep": "output"}); result.Cached {
+			t.Error("Expected a grep to miss the cache")
+		}
+	})
+
+	t.Run("Mutations invalidate their namespace", func(t *testing.T) {
+		tool := newTool(time.Minute)
+		runs(t, tool, "kubectl get pods -n shop", "kubectl get pods -n billing", "kubectl get pods -A", "kubectl get nodes")
+		runs(t, tool, "kubectl delete pod web-1 -n shop")
+		if n := runs(t, tool, "kubectl get pods -n shop", "kubectl get pods -A", "kubectl get nodes"); n != 3 {
+			t.Errorf("Expected shop, all-namespace and cluster results to be dropped, %d of 3 ran", n)
+		}
+		if n := runs(t, tool, "kubectl get pods -n billing"); n != 0 {
+			t.Error("Expected the billing result to stay cached")
+		}
+
+		runs(t, tool, "kubectl apply -f deploy.yaml")
+		if n := runs(t, tool, "kubectl get pods -n billing"); n != 1 {
+			t.Error("Expected a mutation without namespace to drop everything")
+		}
+	})
+
+	t.Run("Not cached", func(t *testing.T) {
+		tool := newTool(time.Minute)
+		for _, command := range []string{
+			"kubectl logs -f web-1",
+			"kubectl get pods --watch",
+			"kubectl exec web-1 -- date",
+			"kubectl get broken",
+		} {
+			if n := runs(t, tool, command, command); n != 2 {
+				t.Errorf("Expected %q to run every time, ran %d of 2", command, n)
+			}
+		}
+	})
+
+	t.Run("Results expire", func(t *testing.T) {
+		tool := newTool(50 * time.Millisecond)
+		runs(t, tool, "kubectl get pods")
+		time.Sleep(100 * time.Millisecond)
+		if n := runs(t, tool, "kubectl get pods"); n != 1 {
+			t.Error("Expected the expired result to be fetched again")
+		}
+	})
+
+	t.Run("A TTL of zero turns the cache off", func(t *testing.T) {
+		tool := newTool(time.Minute)
+		runs(t, tool, "kubectl get pods")
+		tool.Results.SetTTL(0)
+		if tool.Results.Len() != 0 {
+			t.Errorf("Expected turning the cache off to drop its results, have %d", tool.Results.Len())
+		}
+		if n := runs(t, tool, "kubectl get pods", "kubectl get pods"); n != 2 {
+			t.Errorf("Expected every command to run with the cache off, %d of 2 ran", n)
+		}
+		tool.Results.SetTTL(time.Minute)
+		if n := runs(t, tool, "kubectl get pods", "kubectl get pods"); n != 1 {
+			t.Errorf("Expected results to be cached again, %d of 2 ran", n)
+		}
+	})
+
+	t.Run("Context switches miss the cache", func(t *testing.T) {
+		kubeconfig := filepath.Join(t.TempDir(), "config")
+		useContext := func(name string) {
+			t.Helper()
+			if err := os.WriteFile(kubeconfig, []byte("current-context: "+name+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		useContext("staging")
+		tool := newTool(time.Minute)
+		ctx := context.WithValue(ctx, types.KubeconfigKey, kubeconfig)
+		get := func() *types.ExecResult {
+			t.Helper()
+			result, err := tool.Run(ctx, map[string]any{"command": "kubectl get pods"})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			return result.(*types.ExecResult)
+		}
+
+		get()
+		useContext("prod")
+		if get().Cached {
+			t.Error("Expected a result from the previous context not to be served")
+		}
+		if !get().Cached {
+			t.Error("Expected the result to be cached for the new context")
+		}
+		if _, err := tool.Run(ctx, map[string]any{"command": "kubectl config use-context staging"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if tool.Results.Len() != 0 {
+			t.Errorf("Expected a config command to clear the cache, have %d entries", tool.Results.Len())
+		}
+	})
+
+	t.Run("Invalidate keeps other namespaces", func(t *testing.T) {
+		cache := kubectl.NewResultCache(time.Minute)
+		tool := &kubectl.KubectlTool{Results: cache}
+		runs(t, tool, "kubectl get pods -n billing")
+		cache.Invalidate("shop")
+		if cache.Len() != 1 {
+			t.Errorf("Expected the billing result to survive, have %d entries", cache.Len())
+		}
+		cache.Invalidate("billing")
+		if cache.Len() != 0 {
+			t.Errorf("Expected no entries, have %d", cache.Len())
+		}
+	})
+}
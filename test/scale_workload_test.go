@@ -0,0 +1,130 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+)
+
+// scaleKubectl fakes a cluster with deployment "web" at 4 replicas (6 after a
+// scale) and an HPA targeting deployment "api".
+const scaleKubectl = `case "$*" in
+  "get deployments/web -n shop -o json")
+    if [ -f "$(dirname "$0")/scaled" ]; then echo '{"spec": {"replicas": 6}}'; else echo '{"spec": {"replicas": 4}}'; fi ;;
+  "get horizontalpodautoscalers -n shop -o json")
+    echo '{"items": [{"metadata": {"name": "api"}, "spec": {"scaleTargetRef": {"kind": "Deployment", "name": "api"}, "minReplicas": 2, "maxReplicas": 10}}]}' ;;
+  "scale deployments/web -n shop --replicas=6 --current-replicas=4")
+    touch "$(dirname "$0")/scaled"; echo "deployment.apps/web scaled" ;;
+  *)
+    echo "unexpected: $*" >&2; exit 1 ;;
+esac`
+
+func TestScaleWorkloadTool_Run(t *testing.T) {
+	tool := &kubectl.ScaleWorkloadTool{Limits: kubectl.ScaleLimits{MinReplicas: 1, MaxReplicas: 20, MaxDeltaPercent: 50}}
+
+	t.Run("Invalid arguments", func(t *testing.T) {
+		tests := []struct {
+			name string
+			args map[string]any
+			want string
+		}{
+			{"Missing name", map[string]any{"replicas": float64(2)}, "deployment name is required"},
+			{"Missing replicas", map[string]any{"name": "web"}, "replicas is required"},
+			{"Negative replicas", map[string]any{"name": "web", "replicas": float64(-1)}, "must not be negative"},
+			{"Unscalable kind", map[string]any{"kind": "daemonset", "name": "web", "replicas": float64(2)}, "cannot be scaled"},
+			{"Invalid namespace", map[string]any{"name": "web", "namespace": "shop;ls", "replicas": float64(2)}, "invalid namespace"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				result, err := tool.Run(toolContext(t), tt.args)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if res := result.(*kubectl.ScaleWorkloadResult); !strings.Contains(res.Error, tt.want) {
+					t.Errorf("Expected error containing %q, got %q", tt.want, res.Error)
+				}
+			})
+		}
+	})
+
+	t.Run("Guardrails", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			replicas float64
+			want     string
+		}{
+			{"Below minimum", 0, "below the configured minimum"},
+			{"Above maximum", 25, "above the configured maximum"},
+			{"Delta too large", 8, "more than the configured maximum change of 50%"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				logFile := fakeKubectl(t, scaleKubectl)
+
+				result, err := tool.Run(toolContext(t), map[string]any{"name": "web", "namespace": "shop", "replicas": tt.replicas})
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				res := result.(*kubectl.ScaleWorkloadResult)
+				if !strings.Contains(res.Error, tt.want) {
+					t.Errorf("Expected error containing %q, got %q", tt.want, res.Error)
+				}
+				if res.Before == nil || *res.Before != 4 {
+					t.Errorf("Expected before count 4, got %v", res.Before)
+				}
+				for _, call := range invocations(t, logFile) {
+					if strings.HasPrefix(call, "scale") {
+						t.Errorf("Expected no scale command, got %q", call)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Scales and reports before and after", func(t *testing.T) {
+		logFile := fakeKubectl(t, scaleKubectl)
+
+		result, err := tool.Run(toolContext(t), map[string]any{"kind": "deploy", "name": "web", "namespace": "shop", "replicas": float64(6)})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res := result.(*kubectl.ScaleWorkloadResult)
+		if res.Error != "" {
+			t.Fatalf("Unexpected result error: %s", res.Error)
+		}
+		if res.Before == nil || *res.Before != 4 || res.After == nil || *res.After != 6 {
+			t.Errorf("Expected 4 -> 6, got %v -> %v", res.Before, res.After)
+		}
+		if len(res.Warnings) != 0 || res.HPA != "" {
+			t.Errorf("Expected no HPA warning, got %q %v", res.HPA, res.Warnings)
+		}
+		if calls := invocations(t, logFile); len(calls) != 4 {
+			t.Errorf("Expected read, HPA check, scale and read back, got %v", calls)
+		}
+	})
+
+	t.Run("Warns when an HPA targets the workload", func(t *testing.T) {
+		fakeKubectl(t, `case "$*" in
+  "get deployments/api -n shop -o json") echo '{"spec": {"replicas": 3}}' ;;
+  "get horizontalpodautoscalers -n shop -o json") `+"echo '{\"items\": [{\"metadata\": {\"name\": \"api\"}, \"spec\": {\"scaleTargetRef\": {\"kind\": \"Deployment\", \"name\": \"api\"}, \"maxReplicas\": 10}}]}'"+` ;;
+  *) echo "deployment.apps/api scaled" ;;
+esac`)
+
+		result, err := tool.Run(toolContext(t), map[string]any{"name": "api", "namespace": "shop", "replicas": float64(4)})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		res := result.(*kubectl.ScaleWorkloadResult)
+		if res.HPA != "api" || len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "1-10 replicas") {
+			t.Errorf("Expected HPA warning, got %q %v", res.HPA, res.Warnings)
+		}
+	})
+}
+
+func TestScaleWorkloadTool_CheckModifiesResource(t *testing.T) {
+	tool := &kubectl.ScaleWorkloadTool{}
+	if got := tool.CheckModifiesResource(map[string]any{}); got != "yes" {
+		t.Errorf("CheckModifiesResource() = %q, want yes", got)
+	}
+}
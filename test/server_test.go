@@ -7,7 +7,9 @@ import (
 	"strings"
 	"testing"
 
+	"kubectl-go-mcp-server/internal/config"
 	"kubectl-go-mcp-server/internal/mcp"
+	"kubectl-go-mcp-server/pkg/kubectl"
 	"kubectl-go-mcp-server/pkg/types"
 )
 
@@ -270,6 +272,85 @@ func TestNewServer(t *testing.T) {
 			t.Error("kubectl tool should be registered")
 		}
 	})
+
+	t.Run("With config", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.Scale.MaxReplicas = 5
+
+		server, err := mcp.NewServer("/path/to/kubeconfig", "/tmp/workdir", mcp.WithConfig(cfg))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if server.GetConfig() != cfg {
+			t.Error("Server should use the provided config")
+		}
+
+		scale, ok := server.GetTools().Lookup("scale_workload").(*kubectl.ScaleWorkloadTool)
+		if !ok {
+			t.Fatal("scale_workload tool should be registered")
+		}
+		if scale.Limits.MaxReplicas != 5 {
+			t.Errorf("Expected scale limits from config, got %+v", scale.Limits)
+		}
+	})
+}
+
+func TestHandleToolCall_ValidatesArguments(t *testing.T) {
+	logFile := fakeKubectl(t, `echo unexpected`)
+
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	response := handleRequest(t, context.Background(), server, "tools/call", map[string]any{
+		"name":      "scale_workload",
+		"arguments": map[string]any{"name": "web", "replicas": -1},
+	})
+	result, _ := response["result"].(map[string]any)
+	content, _ := result["content"].([]any)
+	if result["isError"] != true || len(content) != 1 {
+		t.Fatalf("Expected an error result, got %v", response)
+	}
+	text, _ := content[0].(map[string]any)["text"].(string)
+	if text != `Invalid arguments: argument "replicas" must be at least 0` {
+		t.Errorf("Unexpected error text: %q", text)
+	}
+	if calls := invocations(t, logFile); len(calls) != 0 {
+		t.Errorf("Expected no kubectl invocations for invalid arguments, got %v", calls)
+	}
+}
+
+func TestHandleToolCall_StructuredContent(t *testing.T) {
+	fakeKubectl(t, `echo 'web-1   Running'`)
+
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	response := handleRequest(t, context.Background(), server, "tools/list", map[string]any{})
+	result, _ := response["result"].(map[string]any)
+	listed, _ := result["tools"].([]any)
+	for _, item := range listed {
+		tool, _ := item.(map[string]any)
+		if schema, _ := tool["outputSchema"].(map[string]any); schema["type"] != "object" {
+			t.Errorf("%s should declare an object output schema, got %v", tool["name"], tool["outputSchema"])
+		}
+	}
+
+	response = handleRequest(t, context.Background(), server, "tools/call", map[string]any{
+		"name":      "kubectl",
+		"arguments": map[string]any{"command": "kubectl get pods"},
+	})
+	result, _ = response["result"].(map[string]any)
+	structured, _ := result["structuredContent"].(map[string]any)
+	if structured["command"] == nil || structured["stdout"] != "web-1   Running\n" {
+		t.Errorf("Expected the ExecResult as structured content, got %v", result)
+	}
+	if content, _ := result["content"].([]any); len(content) != 1 {
+		t.Errorf("Expected a text rendering alongside structured content, got %v", result["content"])
+	}
 }
 
 // Test edge cases and error conditions
@@ -476,21 +557,22 @@ func TestToolsIntegration(t *testing.T) {
 
 // Test security validations in the MCP server
 func TestMCPServerSecurity(t *testing.T) {
-	t.Run("Only kubectl tool is allowed", func(t *testing.T) {
+	t.Run("Only kubectl-backed tools are registered", func(t *testing.T) {
 		server, err := mcp.NewServer("/path/to/kubeconfig", "/tmp/workdir")
 		if err != nil {
 			t.Fatalf("Unexpected error creating server: %v", err)
 		}
 
-		// Verify only kubectl tool is registered
+		expected := []string{"kubectl", "api_resources", "explain", "resource_usage", "node_maintenance", "scale_workload", "fetch_output"}
 		tools := server.GetTools().AllTools()
-		if len(tools) != 1 {
-			t.Errorf("Expected exactly 1 tool, got %d", len(tools))
+		if len(tools) != len(expected) {
+			t.Errorf("Expected exactly %d tools, got %d", len(expected), len(tools))
 		}
 
-		kubectlTool := server.GetTools().Lookup("kubectl")
-		if kubectlTool == nil {
-			t.Error("kubectl tool should be registered")
+		for _, name := range expected {
+			if server.GetTools().Lookup(name) == nil {
+				t.Errorf("%s tool should be registered", name)
+			}
 		}
 
 		// Verify other tools are not registered
@@ -505,6 +587,46 @@ func TestMCPServerSecurity(t *testing.T) {
 		}
 	})
 
+	t.Run("Tools declare annotations", func(t *testing.T) {
+		server, err := mcp.NewServer("/path/to/kubeconfig", "/tmp/workdir")
+		if err != nil {
+			t.Fatalf("Unexpected error creating server: %v", err)
+		}
+
+		response := handleRequest(t, context.Background(), server, "tools/list", map[string]any{})
+		result, _ := response["result"].(map[string]any)
+		listed, _ := result["tools"].([]any)
+		annotations := make(map[string]map[string]any)
+		for _, item := range listed {
+			tool, _ := item.(map[string]any)
+			annotations[tool["name"].(string)], _ = tool["annotations"].(map[string]any)
+		}
+
+		expected := map[string]map[string]bool{
+			"kubectl":          {"readOnlyHint": false, "destructiveHint": true, "openWorldHint": true},
+			"api_resources":    {"readOnlyHint": true, "destructiveHint": false, "openWorldHint": true},
+			"explain":          {"readOnlyHint": true, "destructiveHint": false, "openWorldHint": true},
+			"resource_usage":   {"readOnlyHint": true, "destructiveHint": false, "openWorldHint": true},
+			"node_maintenance": {"readOnlyHint": false, "destructiveHint": true, "openWorldHint": true},
+			"scale_workload":   {"readOnlyHint": false, "destructiveHint": true, "idempotentHint": true, "openWorldHint": true},
+		}
+		for name, hints := range expected {
+			got, ok := annotations[name]
+			if !ok {
+				t.Errorf("%s should declare annotations", name)
+				continue
+			}
+			if title, _ := got["title"].(string); title == "" {
+				t.Errorf("%s should declare a title", name)
+			}
+			for hint, want := range hints {
+				if got[hint] != want {
+					t.Errorf("%s: expected %s=%v, got %v", name, hint, want, got[hint])
+				}
+			}
+		}
+	})
+
 	t.Run("kubectl tool validates commands properly", func(t *testing.T) {
 		server, err := mcp.NewServer("/path/to/kubeconfig", "/tmp/workdir")
 		if err != nil {
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	mcpgo "github.com/mark3labs/mcp-go/mcp"
+	"kubectl-go-mcp-server/internal/config"
+	"kubectl-go-mcp-server/internal/mcp"
+)
+
+type fakeSession struct {
+	id            string
+	notifications chan mcpgo.JSONRPCNotification
+}
+
+func newFakeSession(id string) *fakeSession {
+	return &fakeSession{id: id, notifications: make(chan mcpgo.JSONRPCNotification, 10)}
+}
+
+func (s *fakeSession) Initialize()       {}
+func (s *fakeSession) Initialized() bool { return true }
+func (s *fakeSession) SessionID() string { return s.id }
+func (s *fakeSession) NotificationChannel() chan<- mcpgo.JSONRPCNotification {
+	return s.notifications
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestResourceSubscriptions(t *testing.T) {
+	// The fake watch reports one change and then blocks like a real watch.
+	logFile := fakeKubectl(t, `echo '{"kind": "Deployment", "metadata": {"name": "web"}}'; exec sleep 30`)
+
+	cfg := config.DefaultConfig()
+	cfg.MCP.MaxWatches = 1
+	server, err := mcp.NewServer("", t.TempDir(), mcp.WithConfig(cfg))
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	session := newFakeSession("session-1")
+	ctx := server.GetMCPServer().WithContext(context.Background(), session)
+	if err := server.GetMCPServer().RegisterSession(ctx, session); err != nil {
+		t.Fatalf("Failed to register session: %v", err)
+	}
+
+	const uri = "k8s://_/shop/deployments/web"
+	subscribe := func(uri string) map[string]any {
+		return handleRequest(t, ctx, server, "resources/subscribe", map[string]any{"uri": uri})
+	}
+
+	if response := subscribe(uri); response["error"] != nil {
+		t.Fatalf("Unexpected subscribe error: %v", response["error"])
+	}
+
+	select {
+	case notification := <-session.notifications:
+		if notification.Method != "notifications/resources/updated" || notification.Params.AdditionalFields["uri"] != uri {
+			t.Errorf("Unexpected notification: %+v", notification)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for resources/updated notification")
+	}
+
+	if calls := invocations(t, logFile); len(calls) != 1 || calls[0] != "get deployments.apps web -n shop --watch-only -o json" {
+		t.Errorf("Unexpected kubectl invocations: %v", calls)
+	}
+
+	t.Run("Subscribing again shares the watch", func(t *testing.T) {
+		if response := subscribe(uri); response["error"] != nil {
+			t.Fatalf("Unexpected subscribe error: %v", response["error"])
+		}
+		if n := server.ActiveWatches(); n != 1 {
+			t.Errorf("Expected 1 active watch, got %d", n)
+		}
+	})
+
+	t.Run("Watch limit", func(t *testing.T) {
+		response := subscribe("k8s://_/shop/deployments/api")
+		if response["error"] == nil || !strings.Contains(response["error"].(map[string]any)["message"].(string), "too many active subscriptions") {
+			t.Errorf("Expected watch limit error, got %v", response)
+		}
+	})
+
+	t.Run("Invalid URI", func(t *testing.T) {
+		for _, uri := range []string{"k8s://_/shop/widgets/web", "https://example.com"} {
+			if response := subscribe(uri); response["error"] == nil {
+				t.Errorf("Expected error subscribing to %s, got %v", uri, response)
+			}
+		}
+	})
+
+	t.Run("Unsubscribe stops the watch", func(t *testing.T) {
+		response := handleRequest(t, ctx, server, "resources/unsubscribe", map[string]any{"uri": uri})
+		if response["error"] != nil {
+			t.Fatalf("Unexpected unsubscribe error: %v", response["error"])
+		}
+		waitFor(t, func() bool { return server.ActiveWatches() == 0 })
+	})
+
+	t.Run("Session end stops the watch", func(t *testing.T) {
+		if response := subscribe(uri); response["error"] != nil {
+			t.Fatalf("Unexpected subscribe error: %v", response["error"])
+		}
+		server.GetMCPServer().UnregisterSession(ctx, session.SessionID())
+		waitFor(t, func() bool { return server.ActiveWatches() == 0 })
+	})
+}
+
+func TestResourceSubscriptions_FailingWatch(t *testing.T) {
+	logFile := fakeKubectl(t, `echo 'Error from server (NotFound): deployments.apps "web" not found' >&2; exit 1`)
+
+	server, err := mcp.NewServer("", t.TempDir(), mcp.WithWatchRestartDelay(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	session := &loggingSession{fakeSession: newFakeSession("session-1")}
+	ctx := server.GetMCPServer().WithContext(context.Background(), session)
+	if err := server.GetMCPServer().RegisterSession(ctx, session); err != nil {
+		t.Fatalf("Failed to register session: %v", err)
+	}
+
+	const uri = "k8s://_/shop/deployments/web"
+	if response := handleRequest(t, ctx, server, "resources/subscribe", map[string]any{"uri": uri}); response["error"] != nil {
+		t.Fatalf("Unexpected subscribe error: %v", response["error"])
+	}
+
+	// Warnings about restarts are below the session's log level.
+	select {
+	case notification := <-session.notifications:
+		if notification.Method != "notifications/message" || !strings.Contains(fmt.Sprint(notification.Params.AdditionalFields["data"]), "subscription ended") {
+			t.Errorf("Expected an error log message ending the subscription, got %+v", notification)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the subscription to end")
+	}
+	if n := server.ActiveWatches(); n != 0 {
+		t.Errorf("Expected the watch to be stopped, got %d active", n)
+	}
+	if calls := invocations(t, logFile); len(calls) != 5 {
+		t.Errorf("Expected the watch to stop after 5 attempts, got %d", len(calls))
+	}
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+const podsWideOutput = `NAME    READY   STATUS             RESTARTS      AGE   IP           NODE     NOMINATED NODE   READINESS GATES
+web-1   1/1     Running            0             5d    10.0.0.12    node-1   <none>           <none>
+web-2   0/1     CrashLoopBackOff   7 (2m ago)    5d    10.0.0.13    node-2   <none>           <none>
+db-0    1/1     Running            0             12d   10.0.1.4     node-1   <none>           <none>
+`
+
+func TestParseTables(t *testing.T) {
+	t.Run("Wide output with spaces in headers and cells", func(t *testing.T) {
+		tables, err := kubectl.ParseTables(podsWideOutput)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(tables) != 1 || len(tables[0].Rows) != 3 {
+			t.Fatalf("Expected one table with 3 rows, got %+v", tables)
+		}
+		expectedColumns := []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE", "IP", "NODE", "NOMINATED NODE", "READINESS GATES"}
+		if !reflect.DeepEqual(tables[0].Columns, expectedColumns) {
+			t.Errorf("Expected columns %v, got %v", expectedColumns, tables[0].Columns)
+		}
+		row := tables[0].Rows[1]
+		if row["NAME"] != "web-2" || row["STATUS"] != "CrashLoopBackOff" || row["RESTARTS"] != "7 (2m ago)" || row["NOMINATED NODE"] != "<none>" {
+			t.Errorf("Unexpected row: %v", row)
+		}
+	})
+
+	t.Run("One table per resource type", func(t *testing.T) {
+		output := "NAME        READY   STATUS\npod/web-1   1/1     Running\n\n" +
+			"NAME                 TYPE        CLUSTER-IP   PORT(S)\nservice/kubernetes   ClusterIP   10.96.0.1    443/TCP\n"
+		tables, err := kubectl.ParseTables(output)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(tables) != 2 || tables[0].Rows[0]["NAME"] != "pod/web-1" || tables[1].Rows[0]["PORT(S)"] != "443/TCP" {
+			t.Errorf("Unexpected tables: %+v", tables)
+		}
+	})
+
+	t.Run("Custom columns with empty and multibyte cells", func(t *testing.T) {
+		output := "name    owner   note\nweb-1   team-ü   héllo wörld\nweb-2           \n"
+		tables, err := kubectl.ParseTables(output)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		rows := tables[0].Rows
+		if rows[0]["owner"] != "team-ü" || rows[0]["note"] != "héllo wörld" || rows[1]["owner"] != "" || rows[1]["note"] != "" {
+			t.Errorf("Unexpected rows: %v", rows)
+		}
+	})
+
+	t.Run("Header only", func(t *testing.T) {
+		tables, err := kubectl.ParseTables("NAME   READY\n")
+		if err != nil || len(tables) != 1 || tables[0].Rows == nil || len(tables[0].Rows) != 0 {
+			t.Errorf("Expected an empty table, got %+v, %v", tables, err)
+		}
+	})
+
+	t.Run("Not a table", func(t *testing.T) {
+		for _, output := range []string{"", "\n\n", "  indented: yaml\n"} {
+			if _, err := kubectl.ParseTables(output); err == nil {
+				t.Errorf("Expected %q to be rejected", output)
+			}
+		}
+	})
+}
+
+func TestKubectlTool_ParseTable(t *testing.T) {
+	fakeKubectl(t, "cat <<'EOF'\n"+podsWideOutput+"EOF")
+	tool := &kubectl.KubectlTool{}
+
+	run := func(t *testing.T, args map[string]any) *types.ExecResult {
+		t.Helper()
+		result, err := tool.Run(toolContext(t), args)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return result.(*types.ExecResult)
+	}
+
+	t.Run("Rows alongside the text", func(t *testing.T) {
+		result := run(t, map[string]any{"command": "kubectl get pods -o wide", "parse_table": true})
+		if result.Error != "" || result.Stdout != podsWideOutput {
+			t.Fatalf("Expected the text unchanged, got %+v", result)
+		}
+		if len(result.Tables) != 1 || len(result.Tables[0].Rows) != 3 || result.Tables[0].Rows[2]["IP"] != "10.0.1.4" {
+			t.Errorf("Unexpected tables: %+v", result.Tables)
+		}
+	})
+
+	t.Run("Rows follow grep", func(t *testing.T) {
+		result := run(t, map[string]any{"command": "kubectl get pods", "parse_table": true, "grep": "node-1"})
+		rows := result.Tables[0].Rows
+		if len(rows) != 2 || rows[0]["NAME"] != "web-1" || rows[1]["NAME"] != "db-0" || strings.Count(result.Stdout, "\n") != 2 {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("Not parsed unless asked", func(t *testing.T) {
+		if result := run(t, map[string]any{"command": "kubectl get pods"}); result.Tables != nil {
+			t.Errorf("Expected no tables, got %+v", result.Tables)
+		}
+	})
+
+	t.Run("Needs table output", func(t *testing.T) {
+		for _, args := range []map[string]any{
+			{"command": "kubectl get pods -o yaml", "parse_table": true},
+			{"command": "kubectl get pods", "parse_table": true, "filter": ".items"},
+		} {
+			if result := run(t, args); !strings.Contains(result.Error, "parse_table") {
+				t.Errorf("Expected a parse_table error for %v, got %+v", args, result)
+			}
+		}
+	})
+
+	t.Run("No resources", func(t *testing.T) {
+		fakeKubectl(t, `echo "No resources found in shop namespace." >&2`)
+		result := run(t, map[string]any{"command": "kubectl get pods -n shop", "parse_table": true})
+		if result.Error != "" || result.Tables != nil {
+			t.Errorf("Expected no error and no tables, got %+v", result)
+		}
+	})
+}
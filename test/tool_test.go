@@ -315,6 +315,10 @@ func TestModifiesResource(t *testing.T) {
 		{"Rollout", "kubectl rollout restart deployment app", "yes"},
 		{"Annotate", "kubectl annotate pods my-pod key=value", "yes"},
 		{"Label", "kubectl label pods my-pod key=value", "yes"},
+		{"Cordon", "kubectl cordon node-1", "yes"},
+		{"Uncordon", "kubectl uncordon node-1", "yes"},
+		{"Drain", "kubectl drain node-1 --ignore-daemonsets", "yes"},
+		{"Taint", "kubectl taint nodes node-1 key=value:NoSchedule", "yes"},
 		{"Exec", "kubectl exec pod-name -- ps aux", "no"},
 		{"Port forward", "kubectl port-forward pod-name 8080:80", "no"},
 		{"Proxy", "kubectl proxy", "no"},
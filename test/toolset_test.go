@@ -0,0 +1,310 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"kubectl-go-mcp-server/internal/config"
+	"kubectl-go-mcp-server/internal/mcp"
+	"kubectl-go-mcp-server/pkg/kubectl"
+	"kubectl-go-mcp-server/pkg/types"
+)
+
+func toolNames(server *mcp.Server) []string {
+	var names []string
+	for _, tool := range server.GetTools().AllTools() {
+		names = append(names, tool.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listedToolNames returns the tools clients see in tools/list.
+func listedToolNames(t *testing.T, server *mcp.Server) []string {
+	t.Helper()
+
+	response := handleRequest(t, context.Background(), server, "tools/list", map[string]any{})
+	result, _ := response["result"].(map[string]any)
+	listed, _ := result["tools"].([]any)
+	var names []string
+	for _, item := range listed {
+		tool, _ := item.(map[string]any)
+		names = append(names, tool["name"].(string))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestToolSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings config.ToolSettings
+		readOnly bool
+		noPages  bool
+		expected []string
+	}{
+		{
+			name:     "Defaults offer every tool",
+			expected: []string{"api_resources", "explain", "fetch_output", "kubectl", "node_maintenance", "resource_usage", "scale_workload"},
+		},
+		{
+			name:     "Enabled list",
+			settings: config.ToolSettings{Enabled: []string{"kubectl", "explain"}},
+			expected: []string{"explain", "kubectl"},
+		},
+		{
+			name:     "Disabled list",
+			settings: config.ToolSettings{Disabled: []string{"kubectl", "scale_workload"}},
+			expected: []string{"api_resources", "explain", "fetch_output", "node_maintenance", "resource_usage"},
+		},
+		{
+			name:     "Read-only mode hides modifying tools",
+			readOnly: true,
+			expected: []string{"api_resources", "explain", "fetch_output", "kubectl", "resource_usage"},
+		},
+		{
+			name:     "Without pagination there is no fetch_output",
+			noPages:  true,
+			expected: []string{"api_resources", "explain", "kubectl", "node_maintenance", "resource_usage", "scale_workload"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Tools = tt.settings
+			cfg.MCP.ReadOnly = tt.readOnly
+			cfg.Output.Paginate = !tt.noPages
+
+			server, err := mcp.NewServer("", t.TempDir(), mcp.WithConfig(cfg))
+			if err != nil {
+				t.Fatalf("Unexpected error creating server: %v", err)
+			}
+
+			if got := toolNames(server); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected tools %v, got %v", tt.expected, got)
+			}
+			if got := listedToolNames(t, server); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected tools/list to return %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestKubectlTool_ReadOnly(t *testing.T) {
+	logFile := fakeKubectl(t, `echo ok`)
+	tool := &kubectl.KubectlTool{ReadOnly: true}
+
+	if annotations := tool.FunctionDefinition().Annotations; !annotations.ReadOnlyHint || annotations.DestructiveHint {
+		t.Errorf("Expected read-only annotations, got %+v", annotations)
+	}
+
+	result, err := tool.Run(toolContext(t), map[string]any{"command": "kubectl delete pod web-1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if execResult := result.(*types.ExecResult); !strings.Contains(execResult.Error, "Read-only mode") {
+		t.Errorf("Expected delete to be rejected in read-only mode, got %+v", execResult)
+	}
+
+	result, err = tool.Run(toolContext(t), map[string]any{"command": "kubectl get pods"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if execResult := result.(*types.ExecResult); execResult.Error != "" {
+		t.Errorf("Expected get to be allowed in read-only mode, got %+v", execResult)
+	}
+
+	if calls := invocations(t, logFile); len(calls) != 1 || calls[0] != "get pods" {
+		t.Errorf("Unexpected kubectl invocations: %v", calls)
+	}
+}
+
+func TestIsReadOnlyCommand(t *testing.T) {
+	tests := []struct {
+		command  string
+		readOnly bool
+	}{
+		{"kubectl get pods -n shop", true},
+		{"kubectl logs web-1 --tail 20", true},
+		{"kubectl explain deployment.spec", true},
+		{"kubectl auth can-i delete pods", true},
+		{"kubectl config view", true},
+		{"kubectl config get-contexts", true},
+		{"kubectl config view --raw", false},
+		{"kubectl config use-context prod", false},
+		{"kubectl config set-context --current --namespace shop", false},
+		{"kubectl auth reconcile -f rbac.yaml", false},
+		{"kubectl exec web-1 -- kill 1", false},
+		{"kubectl port-forward web-1 8080:80", false},
+		{"kubectl proxy", false},
+		{"kubectl delete pod web-1", false},
+		{"kubectl", false},
+	}
+	for _, tt := range tests {
+		if got := kubectl.IsReadOnlyCommand(tt.command); got != tt.readOnly {
+			t.Errorf("IsReadOnlyCommand(%q) = %v, expected %v", tt.command, got, tt.readOnly)
+		}
+	}
+}
+
+func TestServer_ApplyConfig(t *testing.T) {
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	session := newFakeSession("session-1")
+	ctx := server.GetMCPServer().WithContext(context.Background(), session)
+	if err := server.GetMCPServer().RegisterSession(ctx, session); err != nil {
+		t.Fatalf("Failed to register session: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Tools.Disabled = []string{"explain"}
+	if err := server.ApplyConfig(cfg); err != nil {
+		t.Fatalf("Unexpected error applying config: %v", err)
+	}
+
+	if server.GetTools().HasTool("explain") {
+		t.Error("explain should be removed after the config disables it")
+	}
+	if server.GetConfig() != cfg {
+		t.Error("Server should use the applied config")
+	}
+	select {
+	case notification := <-session.notifications:
+		if notification.Method != "notifications/tools/list_changed" {
+			t.Errorf("Unexpected notification: %+v", notification)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a tools/list_changed notification")
+	}
+
+	response := handleRequest(t, ctx, server, "tools/call", map[string]any{
+		"name":      "explain",
+		"arguments": map[string]any{"kind": "pods"},
+	})
+	if response["error"] == nil {
+		t.Errorf("Expected calling a disabled tool to fail, got %v", response)
+	}
+}
+
+func TestServer_ApplyConfigReloadsSettings(t *testing.T) {
+	logFile := fakeKubectl(t, `echo "output of $*"`)
+	ctx := toolContext(t)
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+
+	dir := t.TempDir()
+	prompt := "---\ndescription: Check an ingress\n---\nCheck the ingresses with the kubectl tool.\n"
+	if err := os.WriteFile(filepath.Join(dir, "check-ingress.tmpl"), []byte(prompt), 0o644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Prompts.Dir = dir
+	cfg.Cache.TTLSeconds = 0
+	if err := server.ApplyConfig(cfg); err != nil {
+		t.Fatalf("Unexpected error applying config: %v", err)
+	}
+
+	response := handleRequest(t, ctx, server, "prompts/get", map[string]any{"name": "check-ingress"})
+	if response["result"] == nil {
+		t.Errorf("Expected the prompt from the reloaded prompts directory, got %v", response)
+	}
+
+	tool := server.GetTools().Lookup("kubectl")
+	before := len(invocations(t, logFile))
+	for range 2 {
+		if _, err := tool.Run(ctx, map[string]any{"command": "kubectl get pods"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if n := len(invocations(t, logFile)) - before; n != 2 {
+		t.Errorf("Expected the reloaded cache.ttlSeconds of 0 to turn the cache off, %d of 2 commands ran", n)
+	}
+
+	broken := t.TempDir()
+	if err := os.WriteFile(filepath.Join(broken, "broken.tmpl"), []byte("no front matter"), 0o644); err != nil {
+		t.Fatalf("Failed to write prompt: %v", err)
+	}
+	cfg = config.DefaultConfig()
+	cfg.Prompts.Dir = broken
+	if err := server.ApplyConfig(cfg); err == nil {
+		t.Error("Expected a prompts directory with an invalid template to be rejected")
+	}
+	if server.GetConfig().Prompts.Dir != dir {
+		t.Error("Expected the current config to be kept")
+	}
+}
+
+func TestServer_ApplyConfigRejectsInvalidConfig(t *testing.T) {
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+	current := server.GetConfig()
+
+	cfg := config.DefaultConfig()
+	cfg.Output.Normalize.StripFields = []string{`metadata["unterminated`}
+	if err := server.ApplyConfig(cfg); err == nil || !strings.Contains(err.Error(), "stripFields") {
+		t.Errorf("Expected a stripFields error, got %v", err)
+	}
+	if server.GetConfig() != current {
+		t.Error("Expected the current config to be kept")
+	}
+	if _, err := mcp.NewServer("", t.TempDir(), mcp.WithConfig(cfg)); err == nil {
+		t.Error("Expected NewServer to reject the config")
+	}
+}
+
+func TestServer_ApplyConfigKeepsExplainCache(t *testing.T) {
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+	explain := server.GetTools().Lookup("explain")
+	if explain == nil {
+		t.Fatal("Expected the explain tool")
+	}
+	if err := server.ApplyConfig(config.DefaultConfig()); err != nil {
+		t.Fatalf("Unexpected error applying config: %v", err)
+	}
+	if server.GetTools().Lookup("explain") != explain {
+		t.Error("Expected the explain tool, and its cache, to be kept across reloads")
+	}
+}
+
+func TestServer_RefreshTools(t *testing.T) {
+	logFile := fakeKubectl(t, `if [ "$1 $2 $3" = "auth can-i update" ]; then echo no; exit 1; fi
+echo yes`)
+
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+	if err := server.RefreshTools(context.Background()); err != nil {
+		t.Fatalf("Unexpected error refreshing tools: %v", err)
+	}
+
+	if server.GetTools().HasTool("scale_workload") {
+		t.Error("scale_workload should be hidden when scaling is not permitted")
+	}
+	if !server.GetTools().HasTool("node_maintenance") {
+		t.Error("node_maintenance should stay listed when patching nodes is permitted")
+	}
+
+	expected := []string{"auth can-i patch nodes", "auth can-i update deployments.apps --subresource=scale"}
+	calls := invocations(t, logFile)
+	sort.Strings(calls)
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected kubectl invocations %v, got %v", expected, calls)
+	}
+}
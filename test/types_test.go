@@ -1,10 +1,14 @@
 package test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"kubectl-go-mcp-server/internal/mcp"
 	"kubectl-go-mcp-server/pkg/types"
 )
 
@@ -19,6 +23,7 @@ func TestSchemaType_Constants(t *testing.T) {
 		{"types.TypeString", types.TypeString, "string"},
 		{"types.TypeBoolean", types.TypeBoolean, "boolean"},
 		{"types.TypeInteger", types.TypeInteger, "integer"},
+		{"types.TypeNumber", types.TypeNumber, "number"},
 	}
 
 	for _, tt := range tests {
@@ -108,6 +113,128 @@ func TestSchema_ToRawSchema(t *testing.T) {
 	}
 }
 
+func TestSchema_Validate(t *testing.T) {
+	schema := &types.Schema{
+		Type: types.TypeObject,
+		Properties: map[string]*types.Schema{
+			"action":   {Type: types.TypeString, Enum: []any{"cordon", "drain"}},
+			"replicas": {Type: types.TypeInteger, Minimum: types.Ptr(0.0), Maximum: types.Ptr(10.0)},
+			"ratio":    {Type: types.TypeNumber},
+			"name":     {Type: types.TypeString, Pattern: `^[a-z0-9-]+$`},
+			"force":    {Type: types.TypeBoolean},
+			"labels": {
+				Type: types.TypeArray,
+				Items: &types.Schema{
+					Type:                 types.TypeObject,
+					Properties:           map[string]*types.Schema{"key": {Type: types.TypeString}},
+					Required:             []string{"key"},
+					AdditionalProperties: types.Ptr(false),
+				},
+			},
+		},
+		Required: []string{"action"},
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "Valid", args: map[string]any{"action": "drain", "replicas": float64(3), "ratio": 0.5, "name": "web-1", "force": true}},
+		{name: "Undeclared arguments are allowed", args: map[string]any{"action": "cordon", "extra": "x"}},
+		{name: "Null is treated as absent", args: map[string]any{"action": "cordon", "replicas": nil}},
+		{name: "Missing required", args: map[string]any{}, wantErr: `argument "action" is required`},
+		{name: "Null required", args: map[string]any{"action": nil}, wantErr: `argument "action" is required`},
+		{name: "Enum", args: map[string]any{"action": "delete"}, wantErr: `argument "action" must be one of "cordon", "drain"`},
+		{name: "Wrong type", args: map[string]any{"action": "drain", "force": "yes"}, wantErr: `argument "force" must be a boolean, got string`},
+		{name: "Fractional integer", args: map[string]any{"action": "drain", "replicas": 1.5}, wantErr: `argument "replicas" must be an integer, got 1.5`},
+		{name: "Minimum", args: map[string]any{"action": "drain", "replicas": float64(-1)}, wantErr: `argument "replicas" must be at least 0`},
+		{name: "Maximum", args: map[string]any{"action": "drain", "replicas": 11}, wantErr: `argument "replicas" must be at most 10`},
+		{name: "Pattern", args: map[string]any{"action": "drain", "name": "Web_1"}, wantErr: `argument "name" must match pattern "^[a-z0-9-]+$"`},
+		{
+			name:    "Nested additionalProperties",
+			args:    map[string]any{"action": "drain", "labels": []any{map[string]any{"key": "a"}, map[string]any{"key": "b", "value": "c"}}},
+			wantErr: `argument "labels[1].value" is not a known argument`,
+		},
+		{
+			name:    "Nested required",
+			args:    map[string]any{"action": "drain", "labels": []any{map[string]any{}}},
+			wantErr: `argument "labels[0].key" is required`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	var nilSchema *types.Schema
+	if err := nilSchema.Validate(map[string]any{"anything": 1}); err != nil {
+		t.Errorf("A nil schema should accept everything, got %v", err)
+	}
+}
+
+func TestSchemaFor(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name"`
+		Ratio float64 `json:"ratio,omitempty"`
+	}
+	type embedded struct {
+		Source string `json:"source"`
+	}
+	type result struct {
+		embedded
+		Count     int            `json:"count"`
+		Items     []item         `json:"items"`
+		Labels    map[string]any `json:"labels,omitempty"`
+		Started   time.Time      `json:"started"`
+		Before    *int           `json:"before"`
+		Data      []byte         `json:"data,omitempty"`
+		Ignored   string         `json:"-"`
+		Untagged  bool
+		unexposed string
+	}
+
+	schema := types.SchemaFor(&result{})
+	data, err := schema.ToRawSchema()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"type":"object","properties":{` +
+		`"Untagged":{"type":"boolean"},` +
+		`"before":{"type":["integer","null"]},` +
+		`"count":{"type":"integer"},` +
+		`"data":{"type":"string"},` +
+		`"items":{"type":["array","null"],"items":{"type":"object","properties":{"name":{"type":"string"},"ratio":{"type":"number"}},"required":["name"]}},` +
+		`"labels":{"type":"object"},` +
+		`"source":{"type":"string"},` +
+		`"started":{"type":"string"}},` +
+		`"required":["source","count","started","Untagged"]}`
+	if string(data) != expected {
+		t.Errorf("Unexpected schema:\n got %s\nwant %s", data, expected)
+	}
+
+	var encoded map[string]any
+	raw, _ := json.Marshal(&result{Items: []item{{Name: "a"}}})
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := schema.Validate(encoded); err != nil {
+		t.Errorf("Encoded value should match its derived schema: %v", err)
+	}
+}
+
 func TestExecResult_String(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -314,3 +441,52 @@ func TestExecResultJSONSerialization(t *testing.T) {
 		}
 	})
 }
+
+// TestToolOutputSchemas_StandardValidator checks tool results, including the
+// error results whose slices are nil, against the declared output schemas
+// with a standard JSON Schema validator.
+func TestToolOutputSchemas_StandardValidator(t *testing.T) {
+	server, err := mcp.NewServer("", t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error creating server: %v", err)
+	}
+	for _, tool := range server.GetTools().AllTools() {
+		outputSchema := tool.FunctionDefinition().OutputSchema
+		if outputSchema == nil {
+			continue
+		}
+		raw, err := outputSchema.ToRawSchema()
+		if err != nil {
+			t.Fatalf("%s: %v", tool.Name(), err)
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatalf("%s: %v", tool.Name(), err)
+		}
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(tool.Name()+".json", doc); err != nil {
+			t.Fatalf("%s: %v", tool.Name(), err)
+		}
+		schema, err := compiler.Compile(tool.Name() + ".json")
+		if err != nil {
+			t.Fatalf("%s: invalid output schema: %v", tool.Name(), err)
+		}
+
+		// Without a kubeconfig in the context every tool returns an error result.
+		output, err := tool.Run(context.Background(), map[string]any{})
+		if err != nil {
+			t.Fatalf("%s: %v", tool.Name(), err)
+		}
+		encoded, err := json.Marshal(output)
+		if err != nil {
+			t.Fatalf("%s: %v", tool.Name(), err)
+		}
+		instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("%s: %v", tool.Name(), err)
+		}
+		if err := schema.Validate(instance); err != nil {
+			t.Errorf("%s: result %s does not match the output schema: %v", tool.Name(), encoded, err)
+		}
+	}
+}